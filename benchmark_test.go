@@ -0,0 +1,79 @@
+package flaggy_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+)
+
+// newWideParser creates a parser with the specified number of string flags
+// on the root and args that set every tenth flag
+func newWideParser(flagCount int) (*flaggy.Parser, []string) {
+	p := flaggy.NewParser("wide")
+	var args []string
+	for i := 0; i < flagCount; i++ {
+		name := "flag" + strconv.Itoa(i)
+		p.String(new(string), "", name, "a flag")
+		if i%10 == 0 {
+			args = append(args, "--"+name, "value")
+		}
+	}
+	return p, args
+}
+
+// newDeepParser creates a chain of nested subcommands with persistent flags
+// on every level and args that descend to the last subcommand and then set
+// a flag from every level
+func newDeepParser(depth int, flagsPerLevel int) (*flaggy.Parser, []string) {
+	p := flaggy.NewParser("deep")
+	var args []string
+	var flagArgs []string
+	sc := &p.Subcommand
+	for level := 0; level < depth; level++ {
+		child := flaggy.NewSubcommand("level" + strconv.Itoa(level))
+		for i := 0; i < flagsPerLevel; i++ {
+			name := "l" + strconv.Itoa(level) + "f" + strconv.Itoa(i)
+			child.Bool(new(bool), "", name, "a flag")
+			child.SetFlagScope(name, flaggy.PersistentScope)
+		}
+		sc.AttachSubcommand(child, 1)
+		sc = child
+		args = append(args, child.Name)
+		flagArgs = append(flagArgs, "--l"+strconv.Itoa(level)+"f0")
+	}
+	return p, append(args, flagArgs...)
+}
+
+// benchmarkParse parses the args with the parser b.N times
+func benchmarkParse(b *testing.B, p *flaggy.Parser, args []string) {
+	p.AllowReParse = true
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Reset()
+		if err := p.ParseArgs(args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseWide100(b *testing.B) {
+	p, args := newWideParser(100)
+	benchmarkParse(b, p, args)
+}
+
+func BenchmarkParseWide2000(b *testing.B) {
+	p, args := newWideParser(2000)
+	benchmarkParse(b, p, args)
+}
+
+func BenchmarkParseDeep10(b *testing.B) {
+	p, args := newDeepParser(10, 20)
+	benchmarkParse(b, p, args)
+}
+
+func BenchmarkParseDeep50(b *testing.B) {
+	p, args := newDeepParser(50, 40)
+	benchmarkParse(b, p, args)
+}
@@ -0,0 +1,21 @@
+//go:build go1.18
+// +build go1.18
+
+package flaggy
+
+import "runtime/debug"
+
+// addVCSInfo adds the version control details the go command embeds in the
+// build info to the version info
+func addVCSInfo(info *VersionInfo, buildInfo *debug.BuildInfo) {
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		case "vcs.time":
+			info.CommitTime = setting.Value
+		}
+	}
+}
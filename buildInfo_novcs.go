@@ -0,0 +1,10 @@
+//go:build !go1.18
+// +build !go1.18
+
+package flaggy
+
+import "runtime/debug"
+
+// addVCSInfo does nothing because the go command only embeds version control
+// details in the build info since Go 1.18
+func addVCSInfo(info *VersionInfo, buildInfo *debug.BuildInfo) {}
@@ -0,0 +1,176 @@
+package flaggy
+
+import (
+	"flag"
+	"reflect"
+)
+
+// Clone returns a copy of the parser and all of its subcommands, flags and
+// positional values that can parse independently of the original.  Every
+// flag and positional value of the clone assigns to its own copy of the
+// original's assignment var, holding the value it had before the original
+// was first parsed, so any number of clones can parse concurrently.  Read
+// the values parsed by a clone through its Lookup, Visit and VisitAll
+// functions.  Flags of flag.Value types that are not pointers to plain
+// values are shared with the original.
+func (p *Parser) Clone() *Parser {
+	c := *p
+	p.Subcommand.cloneInto(&c.Subcommand)
+	c.Reset()
+	return &c
+}
+
+// Reset clears the results of the last parse so that the parser can parse
+// again.  Every assignment var is restored to the value it had before the
+// parser was first parsed.
+func (p *Parser) Reset() {
+	p.parsed = false
+	p.trailingArgumentsExtracted = false
+	p.helpAllRequested = false
+	p.TrailingArguments = nil
+	p.subcommandContext = &Subcommand{}
+	p.Subcommand.reset()
+}
+
+// cloneInto copies this subcommand and everything attached to it into c
+func (sc *Subcommand) cloneInto(c *Subcommand) {
+	*c = *sc
+
+	c.Flags = nil
+	for _, f := range sc.Flags {
+		newFlag := *f
+		newFlag.AssignmentVar = cloneAssignmentVar(f.AssignmentVar, f.initialValue)
+		newFlag.initialValue = reflect.Value{}
+		newFlag.Validators = append([]Validator(nil), f.Validators...)
+		c.Flags = append(c.Flags, &newFlag)
+	}
+
+	c.PositionalFlags = nil
+	for _, pv := range sc.PositionalFlags {
+		newPositionalValue := *pv
+		newPositionalValue.AssignmentVar = cloneAssignmentVar(pv.AssignmentVar, pv.initialValue)
+		newPositionalValue.initialValue = reflect.Value{}
+		newPositionalValue.Validators = append([]Validator(nil), pv.Validators...)
+		c.PositionalFlags = append(c.PositionalFlags, &newPositionalValue)
+	}
+
+	c.Subcommands = nil
+	for _, cmd := range sc.Subcommands {
+		newSC := &Subcommand{}
+		cmd.cloneInto(newSC)
+		newSC.parent = c
+		c.Subcommands = append(c.Subcommands, newSC)
+	}
+}
+
+// reset clears the results of the last parse on this subcommand and all of
+// its descendants and restores their assignment vars
+func (sc *Subcommand) reset() {
+	sc.Used = false
+	sc.flagIndex = nil
+	sc.ParsedValues = nil
+	sc.TrailingArguments = nil
+	sc.UnknownFlags = nil
+	sc.unknownFlags = nil
+	for _, f := range sc.Flags {
+		restoreAssignmentVar(f.AssignmentVar, f.initialValue)
+		f.set = false
+		f.rawValue = ""
+		f.mapKeysSet = nil
+	}
+	for _, pv := range sc.PositionalFlags {
+		restoreAssignmentVar(pv.AssignmentVar, pv.initialValue)
+		pv.Found = false
+	}
+	for _, cmd := range sc.Subcommands {
+		cmd.reset()
+	}
+}
+
+// snapshot remembers the values of the assignment vars of this subcommand
+// and its descendants the first time they are parsed, so that Reset and
+// Clone can restore them
+func (sc *Subcommand) snapshot() {
+	for _, f := range sc.Flags {
+		if !f.initialValue.IsValid() {
+			f.initialValue = snapshotAssignmentVar(f.AssignmentVar)
+		}
+	}
+	for _, pv := range sc.PositionalFlags {
+		if !pv.initialValue.IsValid() {
+			pv.initialValue = snapshotAssignmentVar(pv.AssignmentVar)
+		}
+	}
+	for _, cmd := range sc.Subcommands {
+		cmd.snapshot()
+	}
+}
+
+// isCopyable indicates that the assignment var is a pointer whose value can
+// be copied.  flag.Value types are excluded because their values may refer to
+// state that can not be copied.
+func isCopyable(assignmentVar interface{}) bool {
+	if _, isValue := assignmentVar.(flag.Value); isValue {
+		return false
+	}
+	return reflect.ValueOf(assignmentVar).Kind() == reflect.Ptr
+}
+
+// snapshotAssignmentVar returns a copy of the value the assignment var
+// points to, or an invalid value if it can not be copied
+func snapshotAssignmentVar(assignmentVar interface{}) reflect.Value {
+	if !isCopyable(assignmentVar) {
+		return reflect.Value{}
+	}
+	return copyValue(reflect.ValueOf(assignmentVar).Elem())
+}
+
+// restoreAssignmentVar sets the value the assignment var points to back to
+// a copy of the snapshot
+func restoreAssignmentVar(assignmentVar interface{}, snapshot reflect.Value) {
+	if !snapshot.IsValid() {
+		return
+	}
+	reflect.ValueOf(assignmentVar).Elem().Set(copyValue(snapshot))
+}
+
+// cloneAssignmentVar returns a new assignment var of the same type holding
+// a copy of the snapshot, or of the current value when there is no snapshot
+func cloneAssignmentVar(assignmentVar interface{}, snapshot reflect.Value) interface{} {
+	if !isCopyable(assignmentVar) {
+		return assignmentVar
+	}
+	if !snapshot.IsValid() {
+		snapshot = reflect.ValueOf(assignmentVar).Elem()
+	}
+	newVar := reflect.New(snapshot.Type())
+	newVar.Elem().Set(copyValue(snapshot))
+	return newVar.Interface()
+}
+
+// copyValue returns a copy of v that does not share the backing arrays of
+// slices or the entries of maps with v
+func copyValue(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, copyValue(v.MapIndex(k)))
+		}
+	default:
+		c.Set(v)
+	}
+	return c
+}
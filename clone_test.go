@@ -0,0 +1,111 @@
+package flaggy_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+)
+
+func newCloneTestParser() *flaggy.Parser {
+	p := flaggy.NewParser("ourtool")
+	var names []string
+	var labels map[string]string
+	serve := flaggy.NewSubcommand("serve")
+	port := 80
+	var target string
+	p.StringSlice(&names, "n", "name", "names")
+	p.StringMap(&labels, "l", "label", "labels")
+	serve.Int(&port, "p", "port", "the port")
+	serve.AddPositionalValue(&target, "target", 1, true, "the target")
+	p.AttachSubcommand(serve, 1)
+	return p
+}
+
+// TestCloneConcurrent tests that clones of one parser can parse in parallel
+// without sharing assignment vars.  Run with -race to detect shared state.
+func TestCloneConcurrent(t *testing.T) {
+	p := newCloneTestParser()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := p.Clone()
+			n := strconv.Itoa(i)
+			err := c.ParseArgs([]string{"serve", "-n", n, "--label", "k=" + n, "--port", n, "target" + n})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			serve := c.TrailingSubcommand()
+			names := *c.Lookup("name").AssignmentVar.(*[]string)
+			labels := *c.Lookup("label").AssignmentVar.(*map[string]string)
+			port := *serve.Lookup("port").AssignmentVar.(*int)
+			target := *serve.PositionalFlags[0].AssignmentVar.(*string)
+			if len(names) != 1 || names[0] != n || len(labels) != 1 || labels["k"] != n || port != i || target != "target"+n {
+				t.Error("clone", i, "parsed incorrect values:", names, labels, port, target)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if names := *p.Lookup("name").AssignmentVar.(*[]string); len(names) != 0 {
+		t.Fatal("expected original parser to be untouched, got", names)
+	}
+}
+
+// TestCloneConcurrentPanicInsteadOfExit tests that exits of clones are
+// handled per parser
+func TestCloneConcurrentPanicInsteadOfExit(t *testing.T) {
+	p := newCloneTestParser()
+	p.PanicInsteadOfExit = true
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected missing positional to panic")
+				}
+			}()
+			c := p.Clone()
+			c.Output = &safeDiscard{}
+			c.ParseArgs([]string{"serve"})
+		}()
+	}
+	wg.Wait()
+}
+
+// safeDiscard discards writes and is safe for concurrent use
+type safeDiscard struct{}
+
+func (safeDiscard) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// TestReset tests that a reset parser can parse again from its initial
+// values
+func TestReset(t *testing.T) {
+	p := newCloneTestParser()
+	for _, n := range []string{"a", "b"} {
+		if err := p.ParseArgs([]string{"-n", n, "-l", "k=" + n, "serve", "t"}); err != nil {
+			t.Fatal(err)
+		}
+		names := *p.Lookup("name").AssignmentVar.(*[]string)
+		if len(names) != 1 || names[0] != n {
+			t.Fatal("expected only the names of this parse, got", names)
+		}
+		if p.Lookup("label").RawValue() != "k="+n {
+			t.Fatal("wrong raw value:", p.Lookup("label").RawValue())
+		}
+		p.Reset()
+	}
+	if port := *p.Subcommands[0].Lookup("port").AssignmentVar.(*int); port != 80 {
+		t.Fatal("expected port to be restored to its default, got", port)
+	}
+}
@@ -0,0 +1,51 @@
+package flaggy
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DebugLogger receives structured debug events while a parser parses.  Args
+// are alternating keys and values, the same as log/slog, so a *slog.Logger
+// can be used directly.
+type DebugLogger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// writerLogger is a DebugLogger that writes each event as a line of
+// key=value pairs
+type writerLogger struct {
+	w io.Writer
+}
+
+// NewDebugLogger returns a DebugLogger that writes each event to w as a line
+// like: flaggy: assigned flag value subcommand=serve flag=port value=80
+func NewDebugLogger(w io.Writer) DebugLogger {
+	return writerLogger{w: w}
+}
+
+// Debug writes the event as a single line
+func (l writerLogger) Debug(msg string, args ...interface{}) {
+	line := "flaggy: " + msg
+	for i := 0; i < len(args); i += 2 {
+		key := fmt.Sprint(args[i])
+		var value interface{} = "!MISSING"
+		if i+1 < len(args) {
+			value = args[i+1]
+		}
+		line = line + " " + key + "=" + formatDebugValue(value)
+	}
+	fmt.Fprintln(l.w, line)
+}
+
+// formatDebugValue formats a value for a debug line, quoting strings that
+// would otherwise be ambiguous
+func formatDebugValue(value interface{}) string {
+	s := fmt.Sprint(value)
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
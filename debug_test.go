@@ -0,0 +1,68 @@
+package flaggy_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+)
+
+// recordingLogger records debug events as lines
+type recordingLogger struct {
+	events []string
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	l.events = append(l.events, strings.TrimSpace(msg+" "+fmt.Sprintln(args...)))
+}
+
+func (l *recordingLogger) contains(event string) bool {
+	for _, e := range l.events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDebugLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	p := flaggy.NewParser("ourtool")
+	p.DebugLogger = logger
+	p.ShowHelpOnUnexpected = false
+	serve := flaggy.NewSubcommand("serve")
+	var port int
+	var target string
+	serve.Int(&port, "p", "port", "the port")
+	serve.AddPositionalValue(&target, "target", 1, false, "the target")
+	p.AttachSubcommand(serve, 1)
+
+	if err := p.ParseArgs([]string{"serve", "-p", "80", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"classified arg subcommand ourtool arg serve type positional",
+		"classified arg subcommand serve arg -p type flagWithSpace",
+		"classified arg subcommand serve arg 80 type flagValue",
+		"descending into subcommand subcommand ourtool child serve position 1",
+		"assigned flag value subcommand serve flag p value 80",
+		"assigned positional value subcommand serve positional target position 1 value web",
+	} {
+		if !logger.contains(want) {
+			t.Errorf("missing debug event %q in:\n%s", want, strings.Join(logger.events, "\n"))
+		}
+	}
+}
+
+func TestNewDebugLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := flaggy.NewDebugLogger(&buf)
+	logger.Debug("assigned flag value", "flag", "name", "value", "a b", "empty", "", "dangling")
+	want := `flaggy: assigned flag value flag=name value="a b" empty="" dangling=!MISSING` + "\n"
+	if buf.String() != want {
+		t.Fatalf("got: %q; want: %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,145 @@
+package flaggy
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes markdown documentation of this parser and each of its
+// subcommands, including their flags and examples, to w
+func (p *Parser) WriteMarkdown(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	level := "#"
+	for _, sc := range p.documentedSubcommands() {
+		out.WriteString(level + " " + sc.commandPath() + "\n\n")
+		level = "##"
+		if sc.Description != "" {
+			out.WriteString(sc.Description + "\n\n")
+		}
+
+		flags := documentedFlags(sc)
+		if len(flags) > 0 {
+			out.WriteString("Flags:\n\n")
+			for _, f := range flags {
+				out.WriteString("- `" + strings.TrimSpace(f.helpLabel()) + "`")
+				if _, description := unquoteUsage(f.Description); description != "" {
+					out.WriteString(": " + description)
+				}
+				out.WriteString("\n")
+			}
+			out.WriteString("\n")
+		}
+
+		if len(sc.Examples) > 0 {
+			out.WriteString("Examples:\n\n")
+			for _, example := range sc.Examples {
+				if example.Description != "" {
+					out.WriteString(example.Description + "\n\n")
+				}
+				out.WriteString("```\n" + example.Command + "\n```\n\n")
+			}
+		}
+	}
+	return out.Flush()
+}
+
+// WriteManPage writes a man page for this parser to w.  Subcommands are
+// listed in a COMMANDS section and the examples of every subcommand in an
+// EXAMPLES section.
+func (p *Parser) WriteManPage(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	out.WriteString(".TH " + manEscape(strings.ToUpper(p.Name)) + " 1 \"\" " + manEscape(p.Version) + "\n")
+	out.WriteString(".SH NAME\n" + manEscape(p.Name))
+	if p.Description != "" {
+		out.WriteString(" \\- " + manEscape(p.Description))
+	}
+	out.WriteString("\n")
+
+	subcommands := p.documentedSubcommands()
+	if flags := documentedFlags(&p.Subcommand); len(flags) > 0 {
+		out.WriteString(".SH OPTIONS\n")
+		writeManFlags(out, flags)
+	}
+
+	if len(subcommands) > 1 {
+		out.WriteString(".SH COMMANDS\n")
+		for _, sc := range subcommands[1:] {
+			out.WriteString(".TP\n.B " + manEscape(sc.commandPath()) + "\n")
+			if sc.Description != "" {
+				out.WriteString(manEscape(sc.Description) + "\n")
+			}
+			if flags := documentedFlags(sc); len(flags) > 0 {
+				out.WriteString(".RS\n")
+				writeManFlags(out, flags)
+				out.WriteString(".RE\n")
+			}
+		}
+	}
+
+	var examples []Example
+	for _, sc := range subcommands {
+		examples = append(examples, sc.Examples...)
+	}
+	if len(examples) > 0 {
+		out.WriteString(".SH EXAMPLES\n")
+		for _, example := range examples {
+			out.WriteString(".TP\n.B " + manEscape(example.Command) + "\n")
+			if example.Description != "" {
+				out.WriteString(manEscape(example.Description) + "\n")
+			}
+		}
+	}
+	return out.Flush()
+}
+
+// documentedSubcommands returns the parser followed by every subcommand
+// nested under it in depth first order, skipping hidden subcommands along
+// with their descendants
+func (p *Parser) documentedSubcommands() []*Subcommand {
+	subcommands := []*Subcommand{&p.Subcommand}
+	var addSubcommands func(sc *Subcommand)
+	addSubcommands = func(sc *Subcommand) {
+		for _, cmd := range sc.Subcommands {
+			if cmd.Hidden {
+				continue
+			}
+			subcommands = append(subcommands, cmd)
+			addSubcommands(cmd)
+		}
+	}
+	addSubcommands(&p.Subcommand)
+	return subcommands
+}
+
+// documentedFlags returns the flags of the subcommand that are not hidden
+func documentedFlags(sc *Subcommand) []*Flag {
+	var flags []*Flag
+	for _, f := range sc.Flags {
+		if !f.Hidden {
+			flags = append(flags, f)
+		}
+	}
+	return flags
+}
+
+// writeManFlags writes each flag as a tagged paragraph of a man page
+func writeManFlags(out *bufio.Writer, flags []*Flag) {
+	for _, f := range flags {
+		out.WriteString(".TP\n.B " + manEscape(strings.TrimSpace(f.helpLabel())) + "\n")
+		if _, description := unquoteUsage(f.Description); description != "" {
+			out.WriteString(manEscape(description) + "\n")
+		}
+	}
+}
+
+// manEscape escapes text for a man page.  Backslashes and dashes are
+// escaped, and lines are kept from starting with a control character.
+func manEscape(text string) string {
+	text = strings.Replace(text, "\\", "\\e", -1)
+	text = strings.Replace(text, "-", "\\-", -1)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = "\\&" + text
+	}
+	return text
+}
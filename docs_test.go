@@ -0,0 +1,64 @@
+package flaggy_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newDocsParser() *flaggy.Parser {
+	p := flaggy.NewParser("ourtool")
+	p.Version = "1.2.3"
+	p.Description = "Manages deployments."
+	var verbose bool
+	var port int
+	p.Bool(&verbose, "v", "verbose", "Verbose output.")
+	serve := flaggy.NewSubcommand("serve")
+	serve.Description = "Serves the app."
+	serve.Int(&port, "p", "port", "The port to listen on.")
+	serve.AddExample("ourtool serve --port 80", "Serve on port 80.")
+	hidden := flaggy.NewSubcommand("hidden")
+	hidden.Hidden = true
+	p.AttachSubcommand(serve, 1)
+	p.AttachSubcommand(hidden, 1)
+	return p
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var out bytes.Buffer
+	if err := newDocsParser().WriteMarkdown(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := "# ourtool\n\n" +
+		"Manages deployments.\n\n" +
+		"Flags:\n\n" +
+		"- `-v --verbose`: Verbose output.\n\n" +
+		"## ourtool serve\n\n" +
+		"Serves the app.\n\n" +
+		"Flags:\n\n" +
+		"- `-p --port int`: The port to listen on.\n\n" +
+		"Examples:\n\n" +
+		"Serve on port 80.\n\n" +
+		"```\nourtool serve --port 80\n```\n\n"
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Errorf("markdown mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteManPage(t *testing.T) {
+	var out bytes.Buffer
+	if err := newDocsParser().WriteManPage(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := ".TH OURTOOL 1 \"\" 1.2.3\n" +
+		".SH NAME\nourtool \\- Manages deployments.\n" +
+		".SH OPTIONS\n.TP\n.B \\-v \\-\\-verbose\nVerbose output.\n" +
+		".SH COMMANDS\n.TP\n.B ourtool serve\nServes the app.\n" +
+		".RS\n.TP\n.B \\-p \\-\\-port int\nThe port to listen on.\n.RE\n" +
+		".SH EXAMPLES\n.TP\n.B ourtool serve \\-\\-port 80\nServe on port 80.\n"
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Errorf("man page mismatch (-want +got):\n%s", diff)
+	}
+}
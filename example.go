@@ -0,0 +1,28 @@
+package flaggy
+
+// Example is an example command line shown in the help of the subcommand it
+// is added to
+type Example struct {
+	Command     string // the full command line, starting with the program name. ex) ourtool serve --port 80
+	Description string // explains what the command line does
+}
+
+// AddExample adds an example command line to the help of this subcommand.
+// The command line starts with the program name and is split into args the
+// same way as response files.
+func (sc *Subcommand) AddExample(command string, description string) {
+	sc.Examples = append(sc.Examples, Example{
+		Command:     command,
+		Description: description,
+	})
+}
+
+// Args returns the args of the example command line without the program
+// name, as they would be passed to ParseArgs
+func (e Example) Args() ([]string, error) {
+	args, err := splitResponseFile(e.Command)
+	if err != nil || len(args) == 0 {
+		return nil, err
+	}
+	return args[1:], nil
+}
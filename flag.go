@@ -2,7 +2,7 @@ package flaggy
 
 import (
 	"errors"
-	"fmt"
+	"flag"
 	"net"
 	"reflect"
 	"strconv"
@@ -20,6 +20,172 @@ type Flag struct {
 	AssignmentVar interface{}
 	defaultValue  string // the value (as a string), that was set by default before any parsing and assignment
 	parsed        bool   // indicates that this flag has already been parsed
+	// NoOptionDefault is the value assigned when the flag is passed without
+	// a value. ex) --color instead of --color=always.  It is only used when
+	// OptionalValue is set.
+	NoOptionDefault string
+	// OptionalValue indicates that the flag may be passed without a value.
+	// A value must then be joined with an equals sign and the following arg
+	// is never consumed.
+	OptionalValue bool
+	// ValueName is the placeholder for the value in help output. ex) WHEN.
+	// When empty, a name in back quotes in the description is used, and then
+	// the value type.
+	ValueName    string
+	MapKeyPolicy MapKeyPolicy // how keys supplied more than once to map flags are handled
+	Validators   []Validator  // run in order after each value is assigned
+	Scope        FlagScope    // determines if descendant subcommands accept this flag
+	Category     string       // groups this flag under its own section in help output
+	mapKeysSet   map[string]bool
+	set          bool          // indicates a value was supplied for this flag during the last parse
+	initialValue reflect.Value // the value of the assignment var before the first parse
+}
+
+// FlagScope determines which subcommands accept a flag
+type FlagScope int
+
+const (
+	// DefaultScope makes a flag accepted by its subcommand and all of the
+	// subcommand's descendants, the same as PersistentScope
+	DefaultScope FlagScope = iota
+	// PersistentScope makes a flag accepted by its subcommand and all of the
+	// subcommand's descendants
+	PersistentScope
+	// LocalScope makes a flag accepted only when its subcommand is the last
+	// subcommand used
+	LocalScope
+)
+
+// isPersistent indicates that this flag is accepted by the descendants of
+// the subcommand it belongs to
+func (f *Flag) isPersistent() bool {
+	return f.Scope != LocalScope
+}
+
+// setValue assigns the value to this flag and runs its validators
+func (f *Flag) setValue(value string) error {
+	if err := f.identifyAndAssignValue(value); err != nil {
+		return err
+	}
+	f.set = true
+	return runValidators(f.Validators, f.AssignmentVar, MessageInvalidFlagValue, f.displayName(), value)
+}
+
+// displayName returns the flag name with dashes as typed by users, preferring
+// the long name. ex) --port
+func (f *Flag) displayName() string {
+	if f.LongName != "" {
+		return "--" + f.LongName
+	}
+	return "-" + f.ShortName
+}
+
+// hasOptionalValue indicates that this flag may be passed without a value
+func (f *Flag) hasOptionalValue() bool {
+	return f.OptionalValue
+}
+
+// helpLabel returns this flag as the default help template displays it,
+// including its names and value placeholder. ex) -p --port int
+func (f *Flag) helpLabel() string {
+	return flagHelpLabel(f.ShortName, f.LongName, f.valueName(), f.hasOptionalValue())
+}
+
+// valueName returns the placeholder used for this flag's value in help
+// output, or an empty string if the flag takes no value
+func (f *Flag) valueName() string {
+	if f.ValueName != "" {
+		return f.ValueName
+	}
+	if name, _ := unquoteUsage(f.Description); name != "" {
+		return name
+	}
+	if valueType := f.ValueType(); valueType != "" {
+		return valueType
+	}
+	if f.hasOptionalValue() {
+		return "VALUE"
+	}
+	return ""
+}
+
+// unquoteUsage extracts the first name in back quotes from a description
+// and returns it along with the description without the back quotes, the
+// same as flag.UnquoteUsage. ex) "a `file` to read" returns "file" and
+// "a file to read"
+func unquoteUsage(description string) (string, string) {
+	start := strings.Index(description, "`")
+	if start < 0 {
+		return "", description
+	}
+	end := strings.Index(description[start+1:], "`")
+	if end < 0 {
+		return "", description
+	}
+	end += start + 1
+	name := description[start+1 : end]
+	return name, description[:start] + name + description[end+1:]
+}
+
+// ValueType returns the type of value this flag takes as shown in help
+// output, or an empty string if the flag takes no value. ex) int or strings
+func (f *Flag) ValueType() string {
+	switch f.AssignmentVar.(type) {
+	case *bool, *[]bool:
+		return ""
+	case *string:
+		return "string"
+	case *[]string:
+		return "strings"
+	case *time.Duration:
+		return "duration"
+	case *[]time.Duration:
+		return "durations"
+	case *float32, *float64:
+		return "float"
+	case *[]float32, *[]float64:
+		return "floats"
+	case *int, *int64, *int32, *int16, *int8:
+		return "int"
+	case *[]int, *[]int64, *[]int32, *[]int16, *[]int8:
+		return "ints"
+	case *uint, *uint64, *uint32, *uint16, *uint8:
+		return "uint"
+	case *[]uint, *[]uint64, *[]uint32, *[]uint16, *[]uint8:
+		return "uints"
+	case *net.IP:
+		return "ip"
+	case *[]net.IP:
+		return "ips"
+	case *net.HardwareAddr:
+		return "mac"
+	case *[]net.HardwareAddr:
+		return "macs"
+	case *net.IPMask:
+		return "mask"
+	case *[]net.IPMask:
+		return "masks"
+	case *DateZ:
+		return "date"
+	case *[]DateZ:
+		return "dates"
+	case *TimeZ:
+		return "time"
+	case *[]TimeZ:
+		return "times"
+	case *flagSetValue:
+		return f.AssignmentVar.(*flagSetValue).valueType
+	}
+	if isBoolValue(f.AssignmentVar) {
+		return ""
+	}
+	if _, isValue := f.AssignmentVar.(flag.Value); isValue {
+		return "value"
+	}
+	if isMapFlag(f.AssignmentVar) {
+		return "key=value"
+	}
+	return ""
 }
 
 // HasName indicates that this flag's short or long name matches the
@@ -32,6 +198,38 @@ func (f *Flag) HasName(name string) bool {
 	return false
 }
 
+// IsSet indicates that a value was supplied for this flag during the last
+// parse, even if it matches the default value
+func (f *Flag) IsSet() bool {
+	return f.set
+}
+
+// RawValue returns the last value supplied for this flag as a string before
+// it was parsed, or an empty string if the flag was not set
+func (f *Flag) RawValue() string {
+	return f.rawValue
+}
+
+// DefaultValue returns the value of this flag as a string before any value
+// was assigned during parsing
+func (f *Flag) DefaultValue() string {
+	f.parseDefaultValue()
+	return f.defaultValue
+}
+
+// parseDefaultValue remembers the current value of the assignment var as the
+// default value.  This is only done once so that assigned values are never
+// mistaken for the default.
+func (f *Flag) parseDefaultValue() error {
+	if f.parsed {
+		return nil
+	}
+	f.parsed = true
+	var err error
+	f.defaultValue, err = f.returnAssignmentVarValueAsString()
+	return err
+}
+
 // identifyAndAssignValue identifies the type of the incoming value
 // and assigns it to the AssignmentVar pointer's target value.  If
 // the value is a type that needs parsing, that is performed as well.
@@ -41,16 +239,10 @@ func (f *Flag) identifyAndAssignValue(value string) error {
 
 	// Only parse this flag default value once. This keeps us from
 	// overwriting the default value in help output
-	if !f.parsed {
-		f.parsed = true
-		// parse the default value as a string and remember it for help output
-		f.defaultValue, err = f.returnAssignmentVarValueAsString()
-		if err != nil {
-			return err
-		}
+	if err = f.parseDefaultValue(); err != nil {
+		return err
 	}
 
-	debugPrint("attempting to assign value", value, "to flag", f.LongName)
 	f.rawValue = value // remember the raw value
 
 	// depending on the type of the assignment variable, we convert the
@@ -357,7 +549,13 @@ func (f *Flag) identifyAndAssignValue(value string) error {
 		existing := f.AssignmentVar.(*[]TimeZ)
 		new := append(*existing, v)
 		*existing = new
+	case flag.Value:
+		v, _ := (f.AssignmentVar).(flag.Value)
+		err = v.Set(value)
 	default:
+		if isMapFlag(f.AssignmentVar) {
+			return f.assignMapValue(value)
+		}
 		return errors.New("Unknown flag assignmentVar supplied in flag " + f.LongName + " " + f.ShortName)
 	}
 
@@ -399,6 +597,24 @@ func determineArgType(arg string) string {
 	return argIsPositional
 }
 
+// isNegativeNumber determines if the specified arg looks like a negative
+// number or duration rather than a flag. ex) -5, -0.5, -1e3 or -1h30m
+func isNegativeNumber(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	if (arg[1] < '0' || arg[1] > '9') && arg[1] != '.' {
+		return false
+	}
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		return true
+	}
+	if _, err := time.ParseDuration(arg); err == nil {
+		return true
+	}
+	return false
+}
+
 // parseArgWithValue parses a key=value concatenated argument into a key and
 // value
 func parseArgWithValue(arg string) (key string, value string) {
@@ -423,13 +639,13 @@ func parseArgWithValue(arg string) (key string, value string) {
 		return args[0], args[1]
 	}
 
-	fmt.Println("Warning: attempted to parseArgWithValue but did not have correct parameter count.", arg, "->", args)
 	return "", ""
 }
 
 // parseFlagToName parses a flag with space value down to a key name:
-//     --path -> path
-//     -p -> p
+//
+//	--path -> path
+//	-p -> p
 func parseFlagToName(arg string) string {
 	// remove minus from start
 	arg = strings.TrimLeft(arg, "-")
@@ -438,9 +654,10 @@ func parseFlagToName(arg string) string {
 }
 
 // collectAllNestedFlags recurses through the command tree to get all
-//     flags specified on a subcommand and its descending subcommands
+//
+//	flags specified on a subcommand and its descending subcommands
 func collectAllNestedFlags(sc *Subcommand) []*Flag {
-	fullList := sc.Flags
+	fullList := append([]*Flag(nil), sc.Flags...)
 	for _, sc := range sc.Subcommands {
 		fullList = append(fullList, sc.Flags...)
 		fullList = append(fullList, collectAllNestedFlags(sc)...)
@@ -448,16 +665,31 @@ func collectAllNestedFlags(sc *Subcommand) []*Flag {
 	return fullList
 }
 
+// contextFlags returns all flags specified on a subcommand, its descending
+// subcommands, its ancestors and the parser
+func contextFlags(sc *Subcommand, p *Parser) []*Flag {
+	var flags []*Flag
+	flags = append(flags, collectAllNestedFlags(sc)...)
+	for parent := sc.parent; parent != nil; parent = parent.parent {
+		flags = append(flags, parent.Flags...)
+	}
+	return append(flags, p.Flags...)
+}
+
+// flagExists determines if a flag with the specified name exists within the
+// specified parser and subcommand's context
+func flagExists(sc *Subcommand, p *Parser, key string) bool {
+	return len(contextFlagsNamed(sc, p, key)) > 0
+}
+
 // flagIsBool determines if the flag is a bool within the specified parser
 // and subcommand's context
 func flagIsBool(sc *Subcommand, p *Parser, key string) bool {
-	for _, f := range append(collectAllNestedFlags(sc), p.Flags...) {
-		if f.HasName(key) {
-			_, isBool := f.AssignmentVar.(*bool)
-			_, isBoolSlice := f.AssignmentVar.(*[]bool)
-			if isBool || isBoolSlice {
-				return true
-			}
+	for _, f := range contextFlagsNamed(sc, p, key) {
+		_, isBool := f.AssignmentVar.(*bool)
+		_, isBoolSlice := f.AssignmentVar.(*[]bool)
+		if isBool || isBoolSlice || isBoolValue(f.AssignmentVar) {
+			return true
 		}
 	}
 
@@ -465,13 +697,37 @@ func flagIsBool(sc *Subcommand, p *Parser, key string) bool {
 	return false
 }
 
+// boolValue is implemented by flag.Value types that do not require a value,
+// like the values of bool flags in the standard library flag package
+type boolValue interface {
+	flag.Value
+	IsBoolFlag() bool
+}
+
+// isBoolValue indicates that the assignment var is a flag.Value that does not
+// require a value
+func isBoolValue(assignmentVar interface{}) bool {
+	v, ok := assignmentVar.(boolValue)
+	return ok && v.IsBoolFlag()
+}
+
+// flagNoOptionDefault returns the value to assign to a flag passed without
+// a value within the specified parser and subcommand's context.  The returned
+// bool is false when the flag requires a value.
+func flagNoOptionDefault(sc *Subcommand, p *Parser, key string) (string, bool) {
+	for _, f := range contextFlagsNamed(sc, p, key) {
+		if f.hasOptionalValue() {
+			return f.NoOptionDefault, true
+		}
+	}
+	return "", false
+}
+
 // returnAssignmentVarValueAsString returns the value of the flag's
 // assignment variable as a string.  This is used to display the
 // default value of flags before they are assigned (like when help is output).
 func (f *Flag) returnAssignmentVarValueAsString() (string, error) {
 
-	debugPrint("returning current value of assignment var of flag", f.LongName)
-
 	var err error
 
 	// depending on the type of the assignment variable, we convert the
@@ -677,7 +933,13 @@ func (f *Flag) returnAssignmentVarValueAsString() (string, error) {
 			strSlice = append(strSlice, d.String())
 		}
 		return strings.Join(strSlice, ","), err
+	case flag.Value:
+		v, _ := (f.AssignmentVar).(flag.Value)
+		return v.String(), nil
 	default:
+		if isMapFlag(f.AssignmentVar) {
+			return f.mapValueAsString()
+		}
 		return "", errors.New("Unknown flag assignmentVar found in flag " + f.LongName + " " + f.ShortName + ". Type not supported: " + reflect.TypeOf(f.AssignmentVar).String())
 	}
 }
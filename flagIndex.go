@@ -0,0 +1,127 @@
+package flaggy
+
+import "strings"
+
+// flagIndex maps flag names to every flag in a command tree with that name so
+// that looking up a flag while parsing does not scan every flag in the tree.
+// The index is built when a parse starts and is dropped when it ends, so
+// flags added between parses are never missed.
+type flagIndex map[string][]indexedFlag
+
+// indexedFlag is a flag in a flag index along with the subcommand it was
+// added to
+type indexedFlag struct {
+	flag  *Flag
+	owner *Subcommand
+}
+
+// indexFlags builds a flag index of this subcommand and all of its
+// descendants and shares it with each of them
+func (sc *Subcommand) indexFlags() {
+	index := make(flagIndex)
+	sc.addToFlagIndex(index)
+}
+
+// addToFlagIndex adds the flags of this subcommand and its descendants to
+// the index, in the order they were added starting with the parent
+func (sc *Subcommand) addToFlagIndex(index flagIndex) {
+	sc.flagIndex = index
+	for _, f := range sc.Flags {
+		for _, name := range f.names() {
+			index[name] = append(index[name], indexedFlag{flag: f, owner: sc})
+		}
+	}
+	for _, cmd := range sc.Subcommands {
+		cmd.addToFlagIndex(index)
+	}
+}
+
+// dropFlagIndexes drops the flag index of this subcommand and all of its
+// descendants
+func (sc *Subcommand) dropFlagIndexes() {
+	sc.flagIndex = nil
+	for _, cmd := range sc.Subcommands {
+		cmd.dropFlagIndexes()
+	}
+}
+
+// names returns the non-empty short and long names of this flag
+func (f *Flag) names() []string {
+	var names []string
+	if f.ShortName != "" {
+		names = append(names, f.ShortName)
+	}
+	if f.LongName != "" {
+		names = append(names, f.LongName)
+	}
+	return names
+}
+
+// isAncestorOf indicates that this subcommand is sc or one of its parents
+func (sc *Subcommand) isAncestorOf(other *Subcommand) bool {
+	for s := other; s != nil; s = s.parent {
+		if s == sc {
+			return true
+		}
+	}
+	return false
+}
+
+// contextFlagsNamed returns the flags with the specified name on a
+// subcommand, its descending subcommands, its ancestors and the parser
+func contextFlagsNamed(sc *Subcommand, p *Parser, name string) []*Flag {
+	var flags []*Flag
+	if sc.flagIndex == nil {
+		for _, f := range contextFlags(sc, p) {
+			if f.HasName(name) {
+				flags = append(flags, f)
+			}
+		}
+		return flags
+	}
+
+	for _, indexed := range sc.flagIndex[strings.TrimSpace(name)] {
+		if indexed.owner.isAncestorOf(sc) || sc.isAncestorOf(indexed.owner) {
+			flags = append(flags, indexed.flag)
+		}
+	}
+	return flags
+}
+
+// ownFlag returns the flag of this subcommand with the specified name, or
+// nil if there is none
+func (sc *Subcommand) ownFlag(name string) *Flag {
+	if sc.flagIndex != nil {
+		for _, indexed := range sc.flagIndex[strings.TrimSpace(name)] {
+			if indexed.owner == sc {
+				return indexed.flag
+			}
+		}
+		return nil
+	}
+	for _, f := range sc.Flags {
+		if f.HasName(name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// persistentFlag returns the flag of this subcommand with the specified name
+// if its descendants inherit it, or nil if there is none
+func (sc *Subcommand) persistentFlag(name string) *Flag {
+	if sc.flagIndex != nil {
+		for _, indexed := range sc.flagIndex[strings.TrimSpace(name)] {
+			if indexed.owner == sc && indexed.flag.isPersistent() {
+				return indexed.flag
+			}
+		}
+		return nil
+	}
+	for _, f := range sc.Flags {
+		if f.HasName(name) && f.isPersistent() {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package flaggy
+
+import "flag"
+
+// flagSetValue is the assignment var of a flag mirrored from a flag.FlagSet.
+// Values are set through the flag set so that it records them as set for
+// flag.Visit and flag.Lookup.
+type flagSetValue struct {
+	flag.Value
+	flagSet   *flag.FlagSet
+	name      string
+	valueType string // the type name the flag package shows in usage
+}
+
+// Set sets the value through the flag set the flag was mirrored from
+func (v *flagSetValue) Set(value string) error {
+	return v.flagSet.Set(v.name, value)
+}
+
+// IsBoolFlag indicates that the mirrored flag does not require a value
+func (v *flagSetValue) IsBoolFlag() bool {
+	return isBoolValue(v.Value)
+}
+
+// AddFlagSet mirrors every flag of a standard library flag.FlagSet into this
+// subcommand, such as the flags libraries register on flag.CommandLine.  The
+// prefix is prepended to each flag name, and hidden hides the flags from help
+// output.  Usage strings and defaults are kept from the flag set.
+func (sc *Subcommand) AddFlagSet(flagSet *flag.FlagSet, prefix string, hidden bool) {
+	flagSet.VisitAll(func(f *flag.Flag) {
+		valueType, _ := flag.UnquoteUsage(&flag.Flag{Value: f.Value})
+		sc.add(&flagSetValue{Value: f.Value, flagSet: flagSet, name: f.Name, valueType: valueType}, "", prefix+f.Name, f.Usage)
+		newFlag := sc.Flags[len(sc.Flags)-1]
+		newFlag.Hidden = hidden
+		newFlag.defaultValue = f.DefValue
+		newFlag.parsed = true
+	})
+}
@@ -0,0 +1,54 @@
+package flaggy_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/diegosz/flaggy"
+)
+
+func TestAddFlagSet(t *testing.T) {
+	fs := flag.NewFlagSet("lib", flag.ContinueOnError)
+	verbosity := fs.Int("v", 0, "log verbosity")
+	logToStderr := fs.Bool("logtostderr", false, "log to standard error")
+	timeout := fs.Duration("timeout", time.Second, "request timeout")
+
+	p := flaggy.NewParser("ourtool")
+	p.AddFlagSet(fs, "lib.", false)
+
+	err := p.ParseArgs([]string{"--lib.v", "3", "-lib.logtostderr", "--lib.timeout=5s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *verbosity != 3 || !*logToStderr || *timeout != 5*time.Second {
+		t.Fatal("flag set values incorrect:", *verbosity, *logToStderr, *timeout)
+	}
+
+	var set []string
+	fs.Visit(func(f *flag.Flag) {
+		set = append(set, f.Name)
+	})
+	if len(set) != 3 {
+		t.Fatal("expected flag set to record the flags as set, got", set)
+	}
+
+	f := p.Lookup("lib.timeout")
+	if f == nil || f.Description != "request timeout" || f.DefaultValue() != "1s" || f.Hidden {
+		t.Fatal("mirrored flag incorrect:", f)
+	}
+	if f.ValueType() != "duration" || p.Lookup("lib.v").ValueType() != "int" || p.Lookup("lib.logtostderr").ValueType() != "" {
+		t.Fatal("mirrored flag value types incorrect:", f.ValueType())
+	}
+}
+
+func TestAddFlagSetHidden(t *testing.T) {
+	fs := flag.NewFlagSet("lib", flag.ContinueOnError)
+	fs.String("vmodule", "", "per-module verbosity")
+
+	p := flaggy.NewParser("ourtool")
+	p.AddFlagSet(fs, "", true)
+	if f := p.Lookup("vmodule"); f == nil || !f.Hidden {
+		t.Fatal("expected hidden mirrored flag, got", f)
+	}
+}
@@ -73,6 +73,29 @@ func TestDetermineArgType(t *testing.T) {
 	}
 }
 
+func TestIsNegativeNumber(t *testing.T) {
+	testCases := map[string]bool{
+		"-5":      true,
+		"-0.5":    true,
+		"-.5":     true,
+		"-1e3":    true,
+		"-1h30m":  true,
+		"-5x":     false,
+		"-inf":    false,
+		"-f":      false,
+		"--5":     false,
+		"5":       false,
+		"-":       false,
+		"-1h=abc": false,
+	}
+
+	for arg, want := range testCases {
+		if got := isNegativeNumber(arg); got != want {
+			t.Fatalf("isNegativeNumber(%s) = %v but expected %v", arg, got, want)
+		}
+	}
+}
+
 // TestInputParsing tests all flag types.
 func TestInputParsing(t *testing.T) {
 	defer debugOff()
@@ -580,3 +603,163 @@ func TestCustomInputParsing(t *testing.T) {
 		}
 	}
 }
+
+// TestMapFlags tests map flags and their duplicate key policies
+func TestMapFlags(t *testing.T) {
+	p := NewParser("TestMapFlags")
+	labels := map[string]string{"env": "dev"}
+	var ports map[string]int
+	var timeouts map[string]time.Duration
+	p.StringMap(&labels, "l", "label", "labels")
+	p.IntMap(&ports, "p", "port", "ports")
+	p.Map(&timeouts, "t", "timeout", "timeouts")
+
+	err := p.ParseArgs([]string{"--label", "env=prod", "-l", "team=core,url=a=b", "-p=http=80,https=443", "-t", "read=1s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels["env"] != "prod" || labels["team"] != "core" || labels["url"] != "a=b" {
+		t.Fatal("string map incorrect", labels)
+	}
+	if ports["http"] != 80 || ports["https"] != 443 {
+		t.Fatal("int map incorrect", ports)
+	}
+	if timeouts["read"] != time.Second {
+		t.Fatal("duration map incorrect", timeouts)
+	}
+	if p.Flags[0].defaultValue != "env=dev" {
+		t.Fatal("map default value incorrect", p.Flags[0].defaultValue)
+	}
+	s, err := p.Flags[0].returnAssignmentVarValueAsString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "env=prod,team=core,url=a=b" {
+		t.Fatal("map value not sorted by key", s)
+	}
+
+	p = NewParser("TestMapFlags")
+	labels = nil
+	p.StringMap(&labels, "l", "label", "labels")
+	err = p.ParseArgs([]string{"-l", "env=prod", "-l", "env=dev"})
+	if err == nil || err.Error() != "Key env supplied more than once for map flag --label" {
+		t.Fatal("expected an error for a duplicate map key, got:", err)
+	}
+
+	p = NewParser("TestMapFlags")
+	labels = nil
+	p.StringMap(&labels, "l", "label", "labels")
+	p.SetMapKeyPolicy("label", MapKeyLastWins)
+	err = p.ParseArgs([]string{"-l", "env=prod", "-l", "env=dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels["env"] != "dev" {
+		t.Fatal("expected last map value to win", labels)
+	}
+
+	p = NewParser("TestMapFlags")
+	collected := map[string][]string{"env": {"default"}}
+	p.Map(&collected, "l", "label", "labels")
+	p.SetMapKeyPolicy("l", MapKeyCollect)
+	err = p.ParseArgs([]string{"-l", "env=prod", "-l", "env=dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collected["env"]) != 2 || collected["env"][0] != "prod" || collected["env"][1] != "dev" {
+		t.Fatal("expected map values to be collected", collected)
+	}
+
+	p = NewParser("TestMapFlags")
+	labels = nil
+	p.StringMap(&labels, "l", "label", "labels")
+	err = p.ParseArgs([]string{"-l", "novalue"})
+	if err == nil {
+		t.Fatal("expected an error for a map value without an equals sign")
+	}
+}
+
+func TestMapNotMap(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected a panic adding a map flag that is not a map")
+		}
+	}()
+	p := NewParser("TestMapNotMap")
+	var name string
+	p.Map(&name, "n", "name", "a name")
+}
+
+func TestSetMapKeyPolicyNotMap(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected a panic setting the map key policy of a flag that is not a map")
+		}
+	}()
+	p := NewParser("TestSetMapKeyPolicyNotMap")
+	var name string
+	p.String(&name, "n", "name", "a name")
+	p.SetMapKeyPolicy("name", MapKeyLastWins)
+}
+
+func TestFlagValueNames(t *testing.T) {
+	p := NewParser("TestFlagValueNames")
+	var port int
+	var file, color string
+	var verbose bool
+	var tags []string
+	var labels map[string]string
+	var level flagTestLevel
+	p.Int(&port, "p", "port", "the port to listen on")
+	p.String(&file, "f", "file", "read the config from `PATH`")
+	p.String(&color, "", "color", "colorize the output")
+	p.Bool(&verbose, "v", "", "verbose output")
+	p.StringSlice(&tags, "", "tag", "tags to apply")
+	p.StringMap(&labels, "", "label", "labels to apply")
+	p.Value(&level, "", "level", "the log level")
+	p.Flags[2].ValueName = "WHEN"
+	p.Flags[2].NoOptionDefault = "always"
+	p.Flags[2].OptionalValue = true
+
+	tests := []struct {
+		name      string
+		valueType string
+		valueName string
+		helpLabel string
+	}{
+		{"port", "int", "int", "-p --port int"},
+		{"file", "string", "PATH", "-f --file PATH"},
+		{"color", "string", "WHEN", "   --color[=WHEN]"},
+		{"v", "", "", "-v"},
+		{"tag", "strings", "strings", "   --tag strings"},
+		{"label", "key=value", "key=value", "   --label key=value"},
+		{"level", "value", "value", "   --level value"},
+	}
+	for _, test := range tests {
+		f := p.Lookup(test.name)
+		if f.ValueType() != test.valueType {
+			t.Errorf("%s: expected value type %q, got %q", test.name, test.valueType, f.ValueType())
+		}
+		if f.valueName() != test.valueName {
+			t.Errorf("%s: expected value name %q, got %q", test.name, test.valueName, f.valueName())
+		}
+		if f.helpLabel() != test.helpLabel {
+			t.Errorf("%s: expected help label %q, got %q", test.name, test.helpLabel, f.helpLabel())
+		}
+	}
+
+	name, description := unquoteUsage("read the config from `PATH`")
+	if name != "PATH" || description != "read the config from PATH" {
+		t.Fatalf("unexpected unquoted usage %q %q", name, description)
+	}
+	name, description = unquoteUsage("an unmatched ` quote")
+	if name != "" || description != "an unmatched ` quote" {
+		t.Fatalf("unexpected unquoted usage %q %q", name, description)
+	}
+}
+
+// flagTestLevel is a flag.Value used to test value names
+type flagTestLevel string
+
+func (l *flagTestLevel) String() string     { return string(*l) }
+func (l *flagTestLevel) Set(s string) error { *l = flagTestLevel(s); return nil }
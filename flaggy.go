@@ -7,7 +7,8 @@
 package flaggy // import "github.com/diegosz/flaggy"
 
 import (
-	"fmt"
+	"flag"
+	"io"
 	"log"
 	"net"
 	"os"
@@ -20,15 +21,21 @@ import (
 const versionFlagLongName = "version"
 const helpFlagLongName = "help"
 const helpFlagShortName = "h"
+const helpAllFlagLongName = "help-all"
 
 // defaultVersion is applied to parsers when they are created
 const defaultVersion = "0.0.0"
 
-// DebugMode indicates that debug output should be enabled
+// DebugMode enables debug output for parsers created after it is set, and
+// for the default parser when it parses.
+//
+// Deprecated: set DebugMode on a Parser instead.
 var DebugMode bool
 
 // DefaultHelpTemplate is the help template that will be used
-// for newly created subcommands and commands
+// for newly created subcommands and commands.
+//
+// Deprecated: use SetHelpTemplate on a Parser instead.
 var DefaultHelpTemplate = defaultHelpTemplate
 
 // DefaultParser is the default parser that is used with the package-level public
@@ -37,6 +44,8 @@ var DefaultParser *Parser
 
 // TrailingArguments holds trailing arguments in the main parser after parsing
 // has been run.
+//
+// Deprecated: use DefaultParser.TrailingArguments instead.
 var TrailingArguments []string
 
 func init() {
@@ -60,6 +69,7 @@ func ResetParser() {
 // Parse parses flags as requested in the default package parser.  All trailing arguments
 // that result from parsing are placed in the global TrailingArguments variable.
 func Parse() {
+	applyDeprecatedGlobals()
 	err := DefaultParser.Parse()
 	TrailingArguments = DefaultParser.TrailingArguments
 	if err != nil {
@@ -71,6 +81,7 @@ func Parse() {
 // running binary.  Targets the default main parser for the package.  All trailing
 // arguments are set in the global TrailingArguments variable.
 func ParseArgs(args []string) {
+	applyDeprecatedGlobals()
 	err := DefaultParser.ParseArgs(args)
 	TrailingArguments = DefaultParser.TrailingArguments
 	if err != nil {
@@ -83,12 +94,51 @@ func String(assignmentVar *string, shortName string, longName string, descriptio
 	DefaultParser.add(assignmentVar, shortName, longName, description)
 }
 
+// Value adds a new flag of any flag.Value type to the default parser
+func Value(assignmentVar flag.Value, shortName string, longName string, description string) {
+	DefaultParser.add(assignmentVar, shortName, longName, description)
+}
+
+// AddFlagSet mirrors every flag of a standard library flag.FlagSet into the
+// default parser
+func AddFlagSet(flagSet *flag.FlagSet, prefix string, hidden bool) {
+	DefaultParser.AddFlagSet(flagSet, prefix, hidden)
+}
+
+// OptionalString adds a new string flag that can be passed with or without
+// a value.  When passed bare, noOptionDefault is assigned.  A value must be
+// joined with an equals sign.  ex) --color or --color=never
+func OptionalString(assignmentVar *string, shortName string, longName string, noOptionDefault string, description string) {
+	DefaultParser.OptionalString(assignmentVar, shortName, longName, noOptionDefault, description)
+}
+
 // StringSlice adds a new slice of strings flag
 // Specify the flag multiple times to fill the slice
 func StringSlice(assignmentVar *[]string, shortName string, longName string, description string) {
 	DefaultParser.add(assignmentVar, shortName, longName, description)
 }
 
+// StringMap adds a new map of strings flag.  Values are key=value pairs
+// split on the first equals sign.  Specify the flag multiple times or
+// separate pairs with commas to fill the map.  ex) --label env=prod,team=core
+func StringMap(assignmentVar *map[string]string, shortName string, longName string, description string) {
+	DefaultParser.add(assignmentVar, shortName, longName, description)
+}
+
+// IntMap adds a new map of ints flag.  Values are key=value pairs split on
+// the first equals sign.  Specify the flag multiple times or separate pairs
+// with commas to fill the map.
+func IntMap(assignmentVar *map[string]int, shortName string, longName string, description string) {
+	DefaultParser.add(assignmentVar, shortName, longName, description)
+}
+
+// Map adds a new map flag with string keys and values of any supported flag
+// type, such as *map[string]time.Duration or *map[string][]string.  Values
+// are key=value pairs split on the first equals sign.
+func Map(assignmentVar interface{}, shortName string, longName string, description string) {
+	DefaultParser.add(assignmentVar, shortName, longName, description)
+}
+
 // Bool adds a new bool flag
 func Bool(assignmentVar *bool, shortName string, longName string, description string) {
 	DefaultParser.add(assignmentVar, shortName, longName, description)
@@ -336,14 +386,19 @@ func SetName(name string) {
 // ShowHelpAndExit shows parser help and exits with status code 2
 func ShowHelpAndExit(message string) {
 	ShowHelp(message)
-	exitOrPanic(2)
+	applyDeprecatedGlobals()
+	DefaultParser.exit(2)
 }
 
-// PanicInsteadOfExit is used when running tests
+// PanicInsteadOfExit makes parsers created after it is set, the default
+// parser when it parses and NewSubcommand panic instead of exiting, used when
+// running tests.
+//
+// Deprecated: set PanicInsteadOfExit or ExitFunc on a Parser instead.
 var PanicInsteadOfExit bool
 
 // exitOrPanic panics instead of calling os.Exit so that tests can catch
-// more failures
+// more failures.  It is used where there is no parser to exit with.
 func exitOrPanic(code int) {
 	if PanicInsteadOfExit {
 		panic("Panic instead of exit with code: " + strconv.Itoa(code))
@@ -351,6 +406,14 @@ func exitOrPanic(code int) {
 	os.Exit(code)
 }
 
+// applyDeprecatedGlobals copies the deprecated DebugMode and
+// PanicInsteadOfExit package variables into the default parser when they
+// changed since they were last copied, so that settings made on the parser
+// itself are kept otherwise
+func applyDeprecatedGlobals() {
+	DefaultParser.copyDeprecatedGlobals()
+}
+
 // ShowHelpOnUnexpectedEnable enables the ShowHelpOnUnexpected behavior on the
 // default parser.  This causes unknown inputs to error out.
 func ShowHelpOnUnexpectedEnable() {
@@ -363,15 +426,111 @@ func ShowHelpOnUnexpectedDisable() {
 	DefaultParser.ShowHelpOnUnexpected = false
 }
 
+// AddHelpTopic adds a help topic to the main parser
+func AddHelpTopic(name string, description string, text string) {
+	DefaultParser.AddHelpTopic(name, description, text)
+}
+
+// LoadMessages reads a catalog of translated messages from a JSON object and
+// adds its messages to the catalog of the main parser
+func LoadMessages(r io.Reader) error {
+	return DefaultParser.LoadMessages(r)
+}
+
+// AddExample adds an example command line to the help of the main parser
+func AddExample(command string, description string) {
+	DefaultParser.AddExample(command, description)
+}
+
+// WriteMarkdown writes markdown documentation of the default parser to w
+func WriteMarkdown(w io.Writer) error {
+	return DefaultParser.WriteMarkdown(w)
+}
+
+// WriteManPage writes a man page for the default parser to w
+func WriteManPage(w io.Writer) error {
+	return DefaultParser.WriteManPage(w)
+}
+
 // AddPositionalValue adds a positional value to the main parser at the global
 // context
 func AddPositionalValue(assignmentVar *string, name string, relativePosition int, required bool, description string) {
 	DefaultParser.AddPositionalValue(assignmentVar, name, relativePosition, required, description)
 }
 
-// debugPrint prints if debugging is enabled
-func debugPrint(i ...interface{}) {
-	if DebugMode {
-		fmt.Println(i...)
-	}
+// AddTypedPositionalValue adds a positional value of any type supported by
+// flags to the main parser at the global context
+func AddTypedPositionalValue(assignmentVar interface{}, name string, relativePosition int, required bool, description string) {
+	DefaultParser.AddTypedPositionalValue(assignmentVar, name, relativePosition, required, description)
+}
+
+// AddVariadicPositionalValue adds a positional value that consumes all
+// remaining positional args into a slice to the main parser at the global
+// context
+func AddVariadicPositionalValue(assignmentVar interface{}, name string, relativePosition int, minCount int, maxCount int, description string) {
+	DefaultParser.AddVariadicPositionalValue(assignmentVar, name, relativePosition, minCount, maxCount, description)
+}
+
+// Validate adds validators to the flag or positional value with the specified
+// name on the default parser
+func Validate(name string, validators ...Validator) {
+	DefaultParser.Validate(name, validators...)
+}
+
+// Lookup returns the flag with the specified short or long name on the
+// default parser, or nil if there is none
+func Lookup(name string) *Flag {
+	return DefaultParser.Lookup(name)
+}
+
+// Visit calls fn for each flag set during the last parse of the default
+// parser
+func Visit(fn func(*Flag)) {
+	DefaultParser.Visit(fn)
+}
+
+// VisitAll calls fn for every flag in the chain of subcommands used by the
+// default parser
+func VisitAll(fn func(*Flag)) {
+	DefaultParser.VisitAll(fn)
+}
+
+// SetHelpTemplate sets the go template the default parser uses when
+// rendering help
+func SetHelpTemplate(tmpl string) error {
+	return DefaultParser.SetHelpTemplate(tmpl)
+}
+
+// SetVersionTemplate sets the go template the default parser uses when
+// rendering version output
+func SetVersionTemplate(tmpl string) error {
+	return DefaultParser.SetVersionTemplate(tmpl)
+}
+
+// SetDebugMode enables or disables debug output on the default parser
+func SetDebugMode(enabled bool) {
+	DefaultParser.DebugMode = enabled
+}
+
+// SetPanicInsteadOfExit makes the default parser panic instead of exiting
+func SetPanicInsteadOfExit(enabled bool) {
+	DefaultParser.PanicInsteadOfExit = enabled
+}
+
+// SetFlagScope sets the scope of the flag with the specified name on the
+// default parser
+func SetFlagScope(name string, scope FlagScope) {
+	DefaultParser.SetFlagScope(name, scope)
+}
+
+// SetFlagCategory sets the category of the flag with the specified name on
+// the default parser
+func SetFlagCategory(name string, category string) {
+	DefaultParser.SetFlagCategory(name, category)
+}
+
+// SetMapKeyPolicy sets how keys supplied more than once are handled for the
+// map flag with the specified name on the default parser
+func SetMapKeyPolicy(name string, policy MapKeyPolicy) {
+	DefaultParser.SetMapKeyPolicy(name, policy)
 }
@@ -0,0 +1,138 @@
+// Package flaggytest runs flaggy parsers in tests without exiting the test
+// binary, swapping os.Stdout or recovering panics.
+//
+//	p := flaggy.NewParser("ourtool")
+//	p.String(&name, "n", "name", "the name")
+//	result := flaggytest.Run(p, "--name", "test")
+//	if result.ExitCode != 0 {
+//		t.Fatal(result.Stderr)
+//	}
+package flaggytest
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/diegosz/flaggy"
+)
+
+// Result holds everything a parser did when it was run against some args
+type Result struct {
+	Stdout     string             // version output
+	Stderr     string             // help and error messages
+	ExitCode   int                // the code the parser exited with, or 0 when it did not exit
+	Exited     bool               // indicates the parser exited, such as after showing help
+	Err        error              // the error returned from parsing
+	Subcommand *flaggy.Subcommand // the last subcommand used
+	Flags      map[string]string  // the raw values of flags set, keyed by long name or short name when there is none
+	Trailing   []string           // the trailing arguments of the parser
+	Parser     *flaggy.Parser     // the parser that was run
+}
+
+// exitCode is panicked by the exit func to stop parsing when the parser
+// exits
+type exitCode int
+
+// Run parses args with the parser and captures its output, exit code and
+// parsed values.  The parser's output writers and exit func are restored
+// before returning.
+func Run(p *flaggy.Parser, args ...string) (result Result) {
+	var stdout, stderr bytes.Buffer
+	output, prevStdout, exitFunc := p.Output, p.Stdout, p.ExitFunc
+	p.Output = &stderr
+	p.Stdout = &stdout
+	p.ExitFunc = func(code int) {
+		panic(exitCode(code))
+	}
+
+	defer func() {
+		p.Output, p.Stdout, p.ExitFunc = output, prevStdout, exitFunc
+		if r := recover(); r != nil {
+			code, ok := r.(exitCode)
+			if !ok {
+				panic(r)
+			}
+			result.Exited = true
+			result.ExitCode = int(code)
+		}
+		result.Stdout = stdout.String()
+		result.Stderr = stderr.String()
+		result.Subcommand = p.TrailingSubcommand()
+		result.Trailing = p.TrailingArguments
+		result.Parser = p
+		result.Flags = make(map[string]string)
+		p.Visit(func(f *flaggy.Flag) {
+			name := f.LongName
+			if name == "" {
+				name = f.ShortName
+			}
+			result.Flags[name] = f.RawValue()
+		})
+	}()
+
+	result.Err = p.ParseArgs(args)
+	return result
+}
+
+// CheckExamples parses the args of every example added to the parser and its
+// subcommands with a clone of the parser, so that examples in help do not go
+// stale.  An error is returned for each example that fails to parse, exits
+// with a non-zero code or does not use the subcommand it was added to.
+//
+//	for _, err := range flaggytest.CheckExamples(p) {
+//		t.Error(err)
+//	}
+func CheckExamples(p *flaggy.Parser) []error {
+	var errs []error
+	var check func(sc *flaggy.Subcommand)
+	check = func(sc *flaggy.Subcommand) {
+		for _, example := range sc.Examples {
+			if err := checkExample(p, sc, example); err != nil {
+				errs = append(errs, fmt.Errorf("example %q of %s: %v", example.Command, sc.Name, err))
+			}
+		}
+		for _, cmd := range sc.Subcommands {
+			check(cmd)
+		}
+	}
+	check(&p.Subcommand)
+	return errs
+}
+
+// checkExample parses the args of an example added to the subcommand with a
+// clone of the parser
+func checkExample(p *flaggy.Parser, sc *flaggy.Subcommand, example flaggy.Example) error {
+	args, err := example.Args()
+	if err != nil {
+		return err
+	}
+	result := Run(p.Clone(), args...)
+	if result.Err != nil {
+		return result.Err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("exited with code %d: %s", result.ExitCode, result.Stderr)
+	}
+	if sc == &p.Subcommand || result.Exited {
+		return nil
+	}
+	if used := commandPath(&result.Parser.Subcommand, result.Subcommand); used != commandPath(&p.Subcommand, sc) {
+		return fmt.Errorf("used subcommand %s", used)
+	}
+	return nil
+}
+
+// commandPath returns the names of the subcommands leading from root to sc,
+// separated by spaces, so that subcommands of a parser and its clones can be
+// compared.  An empty string is returned when sc is not attached under root.
+func commandPath(root *flaggy.Subcommand, sc *flaggy.Subcommand) string {
+	if root == sc {
+		return root.Name
+	}
+	for _, cmd := range root.Subcommands {
+		if path := commandPath(cmd, sc); path != "" {
+			return root.Name + " " + path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package flaggytest_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+	"github.com/diegosz/flaggy/flaggytest"
+)
+
+func newParser() *flaggy.Parser {
+	p := flaggy.NewParser("ourtool")
+	p.Version = "1.2.3"
+	var name string
+	p.String(&name, "n", "name", "the name")
+	serve := flaggy.NewSubcommand("serve")
+	var port int
+	serve.Int(&port, "p", "port", "the port")
+	p.AttachSubcommand(serve, 1)
+	return p
+}
+
+func TestRun(t *testing.T) {
+	result := flaggytest.Run(newParser(), "serve", "--port", "80", "-n", "x", "--", "extra")
+	if result.Err != nil || result.Exited {
+		t.Fatal("unexpected exit or error:", result.Err, result.Stderr)
+	}
+	if result.Subcommand.Name != "serve" {
+		t.Fatal("wrong subcommand:", result.Subcommand.Name)
+	}
+	if result.Flags["port"] != "80" || result.Flags["name"] != "x" || len(result.Flags) != 2 {
+		t.Fatal("wrong flags:", result.Flags)
+	}
+	if len(result.Trailing) != 1 || result.Trailing[0] != "extra" {
+		t.Fatal("wrong trailing arguments:", result.Trailing)
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	result := flaggytest.Run(newParser(), "--version")
+	if !result.Exited || result.ExitCode != 0 {
+		t.Fatal("expected exit code 0, got", result.Exited, result.ExitCode)
+	}
+	if result.Stdout != "Version: 1.2.3\nGo: "+runtime.Version()+"\n" {
+		t.Fatalf("wrong stdout: %q", result.Stdout)
+	}
+}
+
+func TestRunUnknownArgument(t *testing.T) {
+	p := newParser()
+	result := flaggytest.Run(p, "--bogus=1")
+	if !result.Exited || result.ExitCode != 2 {
+		t.Fatal("expected exit code 2, got", result.Exited, result.ExitCode)
+	}
+	if !strings.Contains(result.Stderr, "Unknown arguments supplied:  bogus=1") {
+		t.Fatalf("wrong stderr: %q", result.Stderr)
+	}
+	if p.ExitFunc != nil {
+		t.Fatal("expected exit func to be restored")
+	}
+}
+
+func TestRunAvailableSubcommands(t *testing.T) {
+	p := newParser()
+	p.AddPositionalValue(new(string), "target", 2, false, "the target")
+	result := flaggytest.Run(p, "srve")
+	if result.ExitCode != 2 || !strings.Contains(result.Stderr, "Available subcommands: serve") {
+		t.Fatalf("expected available subcommands on stderr, got %d %q", result.ExitCode, result.Stderr)
+	}
+}
+
+func TestCheckExamples(t *testing.T) {
+	p := newParser()
+	p.AddExample("ourtool --name test", "Sets the name.")
+	serve := p.Subcommands[0]
+	serve.AddExample("ourtool serve --port 80", "Serves on port 80.")
+	serve.AddExample("ourtool serve --port 'eighty'", "Stale example with a bad port.")
+	serve.AddExample("ourtool --name test", "Stale example that does not serve.")
+	serve.AddExample("ourtool serve --help", "Shows help.")
+
+	errs := flaggytest.CheckExamples(p)
+	if len(errs) != 2 {
+		t.Fatal("expected the two stale examples to fail, got", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "eighty") || !strings.Contains(errs[1].Error(), "used subcommand") {
+		t.Fatal("unexpected errors:", errs)
+	}
+}
+
+func TestCheckExamplesSameName(t *testing.T) {
+	p := newParser()
+	remote := flaggy.NewSubcommand("remote")
+	tag := flaggy.NewSubcommand("tag")
+	p.AttachSubcommand(remote, 1)
+	p.AttachSubcommand(tag, 1)
+	remote.AttachSubcommand(flaggy.NewSubcommand("add"), 1)
+	tagAdd := flaggy.NewSubcommand("add")
+	tag.AttachSubcommand(tagAdd, 1)
+	tagAdd.AddExample("ourtool tag add", "Adds a tag.")
+	tagAdd.AddExample("ourtool remote add", "Stale example that adds a remote.")
+
+	errs := flaggytest.CheckExamples(p)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "used subcommand ourtool remote add") {
+		t.Fatal("expected the example using a subcommand of the same name elsewhere to fail, got", errs)
+	}
+}
@@ -3,21 +3,36 @@ package flaggy
 // defaultHelpTemplate is the help template used by default
 // {{if (or (or (gt (len .StringFlags) 0) (gt (len .IntFlags) 0)) (gt (len .BoolFlags) 0))}}
 // {{if (or (gt (len .StringFlags) 0) (gt (len .BoolFlags) 0))}}
-const defaultHelpTemplate = `{{.CommandName}}{{if .Description}} - {{.Description}}{{end}}{{if .PrependMessage}}
+const defaultHelpTemplate = `{{command .CommandPath}}{{if .Description}} - {{wrap .Width (displayWidth (print .CommandPath " - ")) .Description}}{{end}}{{if .PrependMessage}}
 {{.PrependMessage}}{{end}}
 {{if .UsageString}}
-  Usage:
+  {{heading (print (msg "title.usage") ":")}}
     {{.UsageString}}{{end}}{{if .Positionals}}
 
-  Positional Variables: {{range .Positionals}}
-    {{.Name}}  {{.Spacer}}{{if .Description}} {{.Description}}{{end}}{{if .DefaultValue}} (default: {{.DefaultValue}}){{else}}{{if .Required}} (Required){{end}}{{end}}{{end}}{{end}}{{if .Subcommands}}
+  {{heading (print (msg "title.positionals") ":")}} {{range .Positionals}}{{$text := ""}}{{if .Description}}{{$text = print " " .Description}}{{end}}{{if .DefaultValue}}{{$text = print $text " " (default (print "(" (msg "label.default" "value" .DefaultValue) ")"))}}{{else}}{{if .Required}}{{$text = print $text " " (required (print "(" (msg "label.required") ")"))}}{{end}}{{end}}
+    {{.Name}}  {{.Spacer}}{{wrap $.Width (displayWidth (print "    " .Name "  " .Spacer)) $text}}{{end}}{{end}}{{if not (or .SubcommandTree .SubcommandGroups)}}{{if (or .UsageString .Positionals)}}
+{{end}}{{end}}{{if .SubcommandTree}}
 
-  Subcommands: {{range .Subcommands}}
-    {{.LongName}}{{if .ShortName}} ({{.ShortName}}){{end}}{{if .Position}}{{if gt .Position 1}}  (position {{.Position}}){{end}}{{end}}{{if .Description}}   {{.Spacer}}{{.Description}}{{end}}{{end}}
-{{end}}{{if (gt (len .Flags) 0)}}
-  Flags: {{if .Flags}}{{range .Flags}}
-    {{if .ShortName}}-{{.ShortName}} {{else}}   {{end}}{{if .LongName}}--{{.LongName}}{{end}}{{if .Description}}   {{.Spacer}}{{.Description}}{{if .DefaultValue}} (default: {{.DefaultValue}}){{end}}{{end}}{{end}}{{end}}
+  {{heading (print (msg "title.subcommands") ":")}} {{range .SubcommandTree}}{{$names := .LongName}}{{if .ShortName}}{{$names = print $names " (" .ShortName ")"}}{{end}}{{if .Position}}{{if gt .Position 1}}{{$names = print $names "  (" (msg "label.position" "position" (print .Position)) ")"}}{{end}}{{end}}
+    {{.Indent}}{{command $names}}{{if .Description}}{{wrap $.Width (displayWidth (print "    " .Indent $names)) (print "   " .Spacer .Description)}}{{end}}{{end}}
+{{else if .SubcommandGroups}}
+{{range .SubcommandGroups}}
+  {{heading (print .Title ":")}} {{range .Subcommands}}{{$names := .LongName}}{{if .ShortName}}{{$names = print $names " (" .ShortName ")"}}{{end}}{{if .Position}}{{if gt .Position 1}}{{$names = print $names "  (" (msg "label.position" "position" (print .Position)) ")"}}{{end}}{{end}}
+    {{command $names}}{{if .Description}}{{wrap $.Width (displayWidth (print "    " $names)) (print "   " .Spacer .Description)}}{{end}}{{end}}
+{{end}}{{end}}{{if .HelpTopics}}
+  {{heading (print (msg "title.helpTopics") ":")}} {{range .HelpTopics}}
+    {{command .Name}}{{if .Description}}{{wrap $.Width (displayWidth (print "    " .Name)) (print "   " .Spacer .Description)}}{{end}}{{end}}
+{{end}}{{range .FlagGroups}}
+  {{heading (print .Title ":")}} {{range .Flags}}{{$names := "   "}}{{if .ShortName}}{{$names = print "-" .ShortName}}{{if .LongName}}{{$names = print $names " "}}{{end}}{{end}}{{if .LongName}}{{$names = print $names "--" .LongName}}{{end}}{{$names = flag $names}}{{if .OptionalValue}}{{$names = print $names "[=" (placeholder .ValueName) "]"}}{{else if .ValueName}}{{$names = print $names " " (placeholder .ValueName)}}{{end}}
+    {{$names}}{{if .Description}}{{$text := print "   " .LabelSpacer .Description}}{{if .DefaultValue}}{{$text = print $text " " (default (print "(" (msg "label.default" "value" .DefaultValue) ")"))}}{{end}}{{wrap $.Width (displayWidth (print "    " $names)) $text}}{{end}}{{end}}
+{{end}}{{range .InheritedFlags}}
+  {{heading (print .Title ":")}} {{range .Flags}}{{$names := "   "}}{{if .ShortName}}{{$names = print "-" .ShortName}}{{if .LongName}}{{$names = print $names " "}}{{end}}{{end}}{{if .LongName}}{{$names = print $names "--" .LongName}}{{end}}{{$names = flag $names}}{{if .OptionalValue}}{{$names = print $names "[=" (placeholder .ValueName) "]"}}{{else if .ValueName}}{{$names = print $names " " (placeholder .ValueName)}}{{end}}
+    {{$names}}{{if .Description}}{{$text := print "   " .LabelSpacer .Description}}{{if .DefaultValue}}{{$text = print $text " " (default (print "(" (msg "label.default" "value" .DefaultValue) ")"))}}{{end}}{{wrap $.Width (displayWidth (print "    " $names)) $text}}{{end}}{{end}}
+{{end}}{{if .Examples}}
+  {{heading (print (msg "title.examples") ":")}} {{range .Examples}}
+    {{command .Command}}{{if .Description}}
+      {{wrap $.Width 6 .Description}}{{end}}{{end}}
 {{end}}{{if .AppendMessage}}{{.AppendMessage}}
 {{end}}{{if .Message}}
-{{.Message}}{{end}}
+{{message .Message}}{{end}}
 `
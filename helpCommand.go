@@ -0,0 +1,83 @@
+package flaggy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// helpCommandName is the name of the built-in help subcommand
+const helpCommandName = "help"
+
+// Topic is a help topic that is not a command, shown with the built-in help
+// subcommand. ex) mytool help environment
+type Topic struct {
+	Name        string
+	Description string // a short description listed in help output
+	Text        string // the full text shown for the topic
+}
+
+// AddHelpTopic adds a help topic to the parser.  Help topics are listed in
+// the help of the parser and shown with the built-in help subcommand, which
+// is enabled with ShowHelpWithHelpCommand.
+func (p *Parser) AddHelpTopic(name string, description string, text string) {
+	p.HelpTopics = append(p.HelpTopics, Topic{
+		Name:        name,
+		Description: description,
+		Text:        text,
+	})
+}
+
+// hasHelpCommand indicates that the built-in help subcommand is enabled.  A
+// subcommand named help takes precedence over it.
+func (p *Parser) hasHelpCommand() bool {
+	return p.ShowHelpWithHelpCommand && !p.hasSubcommandAtFirstPosition(helpCommandName)
+}
+
+// hasSubcommandAtFirstPosition indicates that the parser has a subcommand at
+// position 1 with the specified name or short name
+func (p *Parser) hasSubcommandAtFirstPosition(name string) bool {
+	for _, cmd := range p.Subcommands {
+		if cmd.Position == 1 && (cmd.Name == name || cmd.ShortName == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// showHelpCommand shows the help of the subcommand at the path of subcommand
+// names, or the text of the help topic with the name, and exits
+func (p *Parser) showHelpCommand(path []string) {
+	p.debug("showing help command", "path", path)
+	if len(path) == 1 {
+		for _, topic := range p.HelpTopics {
+			if topic.Name == path[0] {
+				fmt.Fprintln(p.Output, strings.TrimRight(topic.Text, "\n"))
+				p.exit(0)
+			}
+		}
+	}
+
+	sc := &p.Subcommand
+	for _, name := range path {
+		next := sc.subcommandNamed(name)
+		if next == nil {
+			p.ShowHelpWithMessage(p.Message(MessageUnknownHelpTopic, "path", strings.Join(path, " ")))
+			p.exit(2)
+		}
+		sc = next
+	}
+	p.subcommandContext = sc
+	p.ShowHelp()
+	p.exit(0)
+}
+
+// subcommandNamed returns the subcommand of this subcommand with the
+// specified name or short name, or nil if there is none
+func (sc *Subcommand) subcommandNamed(name string) *Subcommand {
+	for _, cmd := range sc.Subcommands {
+		if cmd.Name == name || cmd.ShortName == name {
+			return cmd
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package flaggy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+	"github.com/diegosz/flaggy/flaggytest"
+)
+
+func newHelpCommandParser() *flaggy.Parser {
+	p := flaggy.NewParser("mytool")
+	p.ShowHelpWithHelpCommand = true
+	p.HelpWidth = -1
+	p.AddHelpTopic("environment", "Environment variables read by mytool.", "MYTOOL_HOME sets the home directory.\n")
+	deploy := flaggy.NewSubcommand("deploy")
+	deploy.ShortName = "d"
+	deploy.Description = "Deploys the app."
+	var zone string
+	deploy.String(&zone, "z", "zone", "The zone to deploy to.")
+	rollback := flaggy.NewSubcommand("rollback")
+	rollback.Description = "Rolls back a deployment."
+	deploy.AttachSubcommand(rollback, 1)
+	p.AttachSubcommand(deploy, 1)
+	return p
+}
+
+func TestHelpCommand(t *testing.T) {
+	result := flaggytest.Run(newHelpCommandParser(), "help", "d", "rollback")
+	if !result.Exited || result.ExitCode != 0 {
+		t.Fatal("expected help to exit with 0, got", result.ExitCode, result.Stderr)
+	}
+	if !strings.HasPrefix(result.Stderr, "mytool deploy rollback - Rolls back a deployment.") {
+		t.Fatal("expected help for rollback, got:", result.Stderr)
+	}
+	if result.Subcommand.Used {
+		t.Fatal("expected rollback not to run")
+	}
+
+	result = flaggytest.Run(newHelpCommandParser(), "help")
+	if result.ExitCode != 0 || !strings.Contains(result.Stderr, "    help     Displays help for a subcommand or help topic.") {
+		t.Fatal("expected root help listing the help subcommand, got:", result.Stderr)
+	}
+	if !strings.Contains(result.Stderr, "  Help Topics: \n    environment   Environment variables read by mytool.\n") {
+		t.Fatal("expected root help listing help topics, got:", result.Stderr)
+	}
+
+	result = flaggytest.Run(newHelpCommandParser(), "help", "environment")
+	if result.ExitCode != 0 || result.Stderr != "MYTOOL_HOME sets the home directory.\n" {
+		t.Fatalf("expected the help topic text, got %q", result.Stderr)
+	}
+
+	result = flaggytest.Run(newHelpCommandParser(), "help", "deploy", "nothing")
+	if result.ExitCode != 2 || !strings.Contains(result.Stderr, "Unknown help topic or subcommand: deploy nothing") {
+		t.Fatal("expected an unknown subcommand error, got:", result.ExitCode, result.Stderr)
+	}
+
+	p := newHelpCommandParser()
+	p.ShowHelpWithHelpCommand = false
+	result = flaggytest.Run(p, "help", "deploy")
+	if result.ExitCode != 0 || !strings.HasPrefix(result.Stderr, "mytool deploy - Deploys the app.") {
+		t.Fatal("expected help to be the built-in help flag when disabled, got:", result.ExitCode, result.Stderr)
+	}
+}
+
+func TestHelpCommandOverridden(t *testing.T) {
+	p := newHelpCommandParser()
+	help := flaggy.NewSubcommand("help")
+	p.AttachSubcommand(help, 1)
+	result := flaggytest.Run(p, "help")
+	if result.Exited || result.Subcommand != help {
+		t.Fatal("expected the help subcommand to run, got:", result.Stderr)
+	}
+}
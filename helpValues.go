@@ -3,21 +3,30 @@ package flaggy
 import (
 	"log"
 	"reflect"
+	"strconv"
 	"strings"
-	"unicode/utf8"
 )
 
 // Help represents the values needed to render a Help page
 type Help struct {
-	Subcommands    []HelpSubcommand
-	Positionals    []HelpPositional
-	Flags          []HelpFlag
-	UsageString    string
-	CommandName    string
-	PrependMessage string
-	AppendMessage  string
-	Message        string
-	Description    string
+	Subcommands      []HelpSubcommand
+	SubcommandGroups []HelpSubcommandGroup // Subcommands grouped into sections by category
+	SubcommandTree   []HelpSubcommand      // every nested subcommand, when the subcommand tree is shown
+	Positionals      []HelpPositional
+	Flags            []HelpFlag      // every flag accepted by the subcommand, including GlobalFlags
+	FlagGroups       []HelpFlagGroup // Flags other than GlobalFlags grouped into sections by category
+	GlobalFlags      []HelpFlag      // persistent flags inherited from parent subcommands
+	InheritedFlags   []HelpFlagGroup // GlobalFlags grouped into sections by the parent they are inherited from, nearest parent first
+	Examples         []HelpExample
+	HelpTopics       []HelpTopic
+	UsageString      string
+	CommandName      string
+	CommandPath      string // the names of the parents of the command followed by its name. ex) app cluster node drain
+	PrependMessage   string
+	AppendMessage    string
+	Message          string
+	Description      string
+	Width            int // the width to wrap help output to, or 0 to not wrap
 }
 
 // HelpSubcommand is used to template subcommand Help output
@@ -27,6 +36,16 @@ type HelpSubcommand struct {
 	Description string
 	Position    int
 	Spacer      string
+	Category    string
+	Depth       int    // the number of subcommands between this one and the subcommand in context
+	Indent      string // indents the subcommand by its depth in the subcommand tree
+}
+
+// HelpSubcommandGroup is used to template a section of subcommands that share
+// a category in Help output
+type HelpSubcommandGroup struct {
+	Title       string // the category, or "Subcommands" for uncategorized subcommands
+	Subcommands []HelpSubcommand
 }
 
 // HelpPositional is used to template positional Help output
@@ -39,13 +58,38 @@ type HelpPositional struct {
 	Spacer       string
 }
 
+// HelpTopic is used to template help topic Help output
+type HelpTopic struct {
+	Name        string
+	Description string
+	Spacer      string
+}
+
+// HelpExample is used to template example Help output
+type HelpExample struct {
+	Command     string
+	Description string
+}
+
 // HelpFlag is used to template string flag Help output
 type HelpFlag struct {
-	ShortName    string
-	LongName     string
-	Description  string
-	DefaultValue string
-	Spacer       string
+	ShortName     string
+	LongName      string
+	Description   string
+	DefaultValue  string
+	Spacer        string // pads the long name to the width of the longest long name
+	LabelSpacer   string // pads the names and value placeholder, as the default template displays them, to a common width
+	ValueName     string // placeholder for the value, or empty if the flag takes no value
+	ValueType     string // the type of value the flag takes, or empty if it takes none
+	OptionalValue bool   // indicates the flag can be passed with or without a value
+	Category      string
+}
+
+// HelpFlagGroup is used to template a section of flags that share a category
+// in Help output
+type HelpFlagGroup struct {
+	Title string // the category, or "Flags" for uncategorized flags
+	Flags []HelpFlag
 }
 
 // ExtractValues extracts Help template values from a subcommand and its parent
@@ -54,6 +98,7 @@ type HelpFlag struct {
 func (h *Help) ExtractValues(p *Parser, message string) {
 	// accept message string for output
 	h.Message = message
+	h.Width = p.helpWidth()
 
 	// extract Help values from the current subcommand in context
 	// prependMessage string
@@ -62,10 +107,23 @@ func (h *Help) ExtractValues(p *Parser, message string) {
 	h.AppendMessage = p.subcommandContext.AdditionalHelpAppend
 	// command name
 	h.CommandName = p.subcommandContext.Name
+	h.CommandPath = p.subcommandContext.commandPath()
 	// description
 	h.Description = p.subcommandContext.Description
 
+	// the built-in subcommands are only available at the root
+	isRoot := p.subcommandContext.parent == nil
+	var builtinCommands []HelpSubcommand
+	if isRoot {
+		builtinCommands = p.builtinHelpSubcommands()
+	}
+
 	maxLength := getLongestNameLength(p.subcommandContext.Subcommands, 0)
+	for _, cmd := range builtinCommands {
+		if len(cmd.LongName) > maxLength {
+			maxLength = len(cmd.LongName)
+		}
+	}
 
 	// subcommands    []HelpSubcommand
 	for _, cmd := range p.subcommandContext.Subcommands {
@@ -78,9 +136,36 @@ func (h *Help) ExtractValues(p *Parser, message string) {
 			Description: cmd.Description,
 			Position:    cmd.Position,
 			Spacer:      makeSpacer(cmd.Name, maxLength),
+			Category:    cmd.Category,
 		}
 		h.Subcommands = append(h.Subcommands, newHelpSubcommand)
 	}
+	for _, cmd := range builtinCommands {
+		cmd.Spacer = makeSpacer(cmd.LongName, maxLength)
+		h.Subcommands = append(h.Subcommands, cmd)
+	}
+
+	// every nested subcommand is listed when the subcommand tree is shown
+	if p.ShowSubcommandTree || p.helpAllRequested {
+		h.SubcommandTree = p.makeHelpSubcommandTree(p.subcommandContext, builtinCommands)
+	}
+
+	// help topics are listed at the root
+	if isRoot {
+		maxLength = 0
+		for _, topic := range p.HelpTopics {
+			if displayWidth(topic.Name) > maxLength {
+				maxLength = displayWidth(topic.Name)
+			}
+		}
+		for _, topic := range p.HelpTopics {
+			h.HelpTopics = append(h.HelpTopics, HelpTopic{
+				Name:        topic.Name,
+				Description: topic.Description,
+				Spacer:      makeSpacer(topic.Name, maxLength),
+			})
+		}
+	}
 
 	maxLength = getLongestNameLength(p.subcommandContext.PositionalFlags, 0)
 
@@ -90,31 +175,54 @@ func (h *Help) ExtractValues(p *Parser, message string) {
 			continue
 		}
 		newHelpPositional := HelpPositional{
-			Name:         pos.Name,
+			Name:         pos.helpName(),
 			Position:     pos.Position,
 			Description:  pos.Description,
 			Required:     pos.Required,
 			DefaultValue: pos.defaultValue,
-			Spacer:       makeSpacer(pos.Name, maxLength),
+			Spacer:       makeSpacer(pos.helpName(), maxLength),
 		}
 		h.Positionals = append(h.Positionals, newHelpPositional)
 	}
 
+	// flags are padded both after their long names and after their labels
+	versionLabel := flagHelpLabel("", versionFlagLongName, "", false)
+	helpLabel := flagHelpLabel(helpFlagShortName, helpFlagLongName, "", false)
+	helpAllLabel := flagHelpLabel("", helpAllFlagLongName, "", false)
 	maxLength = len(versionFlagLongName)
 	if len(helpFlagLongName) > maxLength {
 		maxLength = len(helpFlagLongName)
 	}
+	maxLabelLength := displayWidth(versionLabel)
+	if displayWidth(helpLabel) > maxLabelLength {
+		maxLabelLength = displayWidth(helpLabel)
+	}
+	if p.ShowHelpAllWithHelpAllFlag {
+		if len(helpAllFlagLongName) > maxLength {
+			maxLength = len(helpAllFlagLongName)
+		}
+		if displayWidth(helpAllLabel) > maxLabelLength {
+			maxLabelLength = displayWidth(helpAllLabel)
+		}
+	}
 	maxLength = getLongestNameLength(p.subcommandContext.Flags, maxLength)
 	maxLength = getLongestNameLength(p.Flags, maxLength)
+	maxLabelLength = getLongestFlagLabelLength(p.subcommandContext.Flags, maxLabelLength)
+	maxLabelLength = getLongestFlagLabelLength(p.Flags, maxLabelLength)
+	for parent := p.subcommandContext.parent; parent != nil; parent = parent.parent {
+		maxLength = getLongestNameLength(parent.Flags, maxLength)
+		maxLabelLength = getLongestFlagLabelLength(parent.Flags, maxLabelLength)
+	}
 
 	// if the built-in version flag is enabled, then add it as a help flag
 	if p.ShowVersionWithVersionFlag {
 		defaultVersionFlag := HelpFlag{
 			ShortName:    "",
 			LongName:     versionFlagLongName,
-			Description:  "Displays the program version string.",
+			Description:  p.Message(MessageVersionFlag),
 			DefaultValue: "",
 			Spacer:       makeSpacer(versionFlagLongName, maxLength),
+			LabelSpacer:  makeSpacer(versionLabel, maxLabelLength),
 		}
 		h.Flags = append(h.Flags, defaultVersionFlag)
 	}
@@ -124,18 +232,71 @@ func (h *Help) ExtractValues(p *Parser, message string) {
 		defaultHelpFlag := HelpFlag{
 			ShortName:    helpFlagShortName,
 			LongName:     helpFlagLongName,
-			Description:  "Displays help with available flag, subcommand, and positional value parameters.",
+			Description:  p.Message(MessageHelpFlag),
 			DefaultValue: "",
 			Spacer:       makeSpacer(helpFlagLongName, maxLength),
+			LabelSpacer:  makeSpacer(helpLabel, maxLabelLength),
 		}
 		h.Flags = append(h.Flags, defaultHelpFlag)
 	}
 
+	// if the built-in help-all flag exists, then add it as a help flag
+	if p.ShowHelpAllWithHelpAllFlag {
+		defaultHelpAllFlag := HelpFlag{
+			LongName:    helpAllFlagLongName,
+			Description: p.Message(MessageHelpAllFlag),
+			Spacer:      makeSpacer(helpAllFlagLongName, maxLength),
+			LabelSpacer: makeSpacer(helpAllLabel, maxLabelLength),
+		}
+		h.Flags = append(h.Flags, defaultHelpAllFlag)
+	}
+
 	// go through every flag in the subcommand and add it to help output
-	h.parseFlagsToHelpFlags(p.subcommandContext.Flags, maxLength)
+	h.parseFlagsToHelpFlags(p.subcommandContext.Flags, maxLength, maxLabelLength)
 
-	// go through every flag in the parent parser and add it to help output
-	h.parseFlagsToHelpFlags(p.Flags, maxLength)
+	// the parser's flags belong to the root, so they are local flags when no
+	// subcommand is in context
+	if p.subcommandContext.parent == nil {
+		h.parseFlagsToHelpFlags(p.Flags, maxLength, maxLabelLength)
+	}
+
+	// go through the persistent flags of every parent and add them to the
+	// global flags in help output
+	for parent := p.subcommandContext.parent; parent != nil; parent = parent.parent {
+		var inherited []*Flag
+		for _, f := range parent.Flags {
+			if f.isPersistent() {
+				inherited = append(inherited, f)
+			}
+		}
+		group := HelpFlagGroup{Title: p.Message(MessageInheritedFlagsTitle, "command", parent.commandPath())}
+		if parent.parent == nil {
+			group.Title = p.Message(MessageGlobalFlagsTitle)
+		}
+		for _, f := range makeHelpFlags(inherited, maxLength, maxLabelLength) {
+			if !containsHelpFlag(h.Flags, f) && !containsHelpFlag(h.GlobalFlags, f) {
+				h.GlobalFlags = append(h.GlobalFlags, f)
+				group.Flags = append(group.Flags, f)
+			}
+		}
+		if len(group.Flags) > 0 {
+			h.InheritedFlags = append(h.InheritedFlags, group)
+		}
+	}
+
+	// the flat list of flags includes the inherited flags, while the
+	// categorized sections only hold the subcommand's own flags
+	localFlags := h.Flags
+	h.Flags = append(append([]HelpFlag(nil), localFlags...), h.GlobalFlags...)
+	h.groupByCategory(p, localFlags)
+
+	// examples
+	for _, example := range p.subcommandContext.Examples {
+		h.Examples = append(h.Examples, HelpExample{
+			Command:     example.Command,
+			Description: example.Description,
+		})
+	}
 
 	// formulate the usage string
 	// first, we capture all the command and positional names by position
@@ -145,9 +306,9 @@ func (h *Help) ExtractValues(p *Parser, message string) {
 			continue
 		}
 		if len(commandsByPosition[pos.Position]) > 0 {
-			commandsByPosition[pos.Position] = commandsByPosition[pos.Position] + "|" + pos.Name
+			commandsByPosition[pos.Position] = commandsByPosition[pos.Position] + "|" + pos.helpName()
 		} else {
-			commandsByPosition[pos.Position] = pos.Name
+			commandsByPosition[pos.Position] = pos.helpName()
 		}
 	}
 	for _, cmd := range p.subcommandContext.Subcommands {
@@ -169,11 +330,12 @@ func (h *Help) ExtractValues(p *Parser, message string) {
 		}
 	}
 
-	// only have a usage string if there are positional items
+	// only have a usage string if there are positional items or parents
 	var usageString string
-	if highestPosition > 0 {
-		// find each positional value and make our final string
-		usageString = p.subcommandContext.Name
+	if highestPosition > 0 || p.subcommandContext.parent != nil {
+		// find each positional value and make our final string, starting with
+		// the parents and their positional values
+		usageString = p.subcommandContext.usagePath()
 		for i := 1; i <= highestPosition; i++ {
 			if len(commandsByPosition[i]) > 0 {
 				usageString = usageString + " [" + commandsByPosition[i] + "]"
@@ -188,20 +350,57 @@ func (h *Help) ExtractValues(p *Parser, message string) {
 	h.UsageString = usageString
 }
 
+// commandPath returns the names of the parents of this subcommand followed by
+// its name, separated by spaces. ex) app cluster node drain
+func (sc *Subcommand) commandPath() string {
+	if sc.parent == nil {
+		return sc.Name
+	}
+	return sc.parent.commandPath() + " " + sc.Name
+}
+
+// usagePath returns the command path of this subcommand for usage strings,
+// including the positional values of each parent that come before the
+// subcommand they lead to. ex) app [region] cluster node
+func (sc *Subcommand) usagePath() string {
+	if sc.parent == nil {
+		return sc.Name
+	}
+	usagePath := sc.parent.usagePath()
+	for position := 1; position < sc.Position; position++ {
+		var names []string
+		for _, pos := range sc.parent.PositionalFlags {
+			if pos.Position == position && !pos.Hidden {
+				names = append(names, pos.helpName())
+			}
+		}
+		if len(names) > 0 {
+			usagePath = usagePath + " [" + strings.Join(names, "|") + "]"
+		}
+	}
+	return usagePath + " " + sc.Name
+}
+
 // parseFlagsToHelpFlags parses the specified slice of flags into
 // help flags on the the calling help command
-func (h *Help) parseFlagsToHelpFlags(flags []*Flag, maxLength int) {
+func (h *Help) parseFlagsToHelpFlags(flags []*Flag, maxLength int, maxLabelLength int) {
+	for _, f := range makeHelpFlags(flags, maxLength, maxLabelLength) {
+		h.AddFlagToHelp(f)
+	}
+}
+
+// makeHelpFlags converts the specified slice of flags into help flags,
+// skipping hidden flags.  Long names are padded to maxLength and labels to
+// maxLabelLength.
+func makeHelpFlags(flags []*Flag, maxLength int, maxLabelLength int) []HelpFlag {
+	var helpFlags []HelpFlag
 	for _, f := range flags {
 		if f.Hidden {
 			continue
 		}
 
 		// parse help values out if the flag hasn't been parsed yet
-		if !f.parsed {
-			f.parsed = true
-			// parse the default value as a string and remember it for help output
-			f.defaultValue, _ = f.returnAssignmentVarValueAsString()
-		}
+		f.parseDefaultValue()
 
 		// determine the default value based on the assignment variable
 		defaultValue := f.defaultValue
@@ -219,29 +418,179 @@ func (h *Help) parseFlagsToHelpFlags(flags []*Flag, maxLength int) {
 				defaultValue = ""
 			}
 		}
+		if isBoolValue(f.AssignmentVar) && defaultValue == "false" {
+			defaultValue = ""
+		}
+
+		// back quotes only mark the value name in descriptions
+		_, description := unquoteUsage(f.Description)
 
 		newHelpFlag := HelpFlag{
-			ShortName:    f.ShortName,
-			LongName:     f.LongName,
-			Description:  f.Description,
-			DefaultValue: defaultValue,
-			Spacer:       makeSpacer(f.LongName, maxLength),
+			ShortName:     f.ShortName,
+			LongName:      f.LongName,
+			Description:   description,
+			DefaultValue:  defaultValue,
+			Spacer:        makeSpacer(f.LongName, maxLength),
+			LabelSpacer:   makeSpacer(f.helpLabel(), maxLabelLength),
+			ValueName:     f.valueName(),
+			ValueType:     f.ValueType(),
+			OptionalValue: f.hasOptionalValue(),
+			Category:      f.Category,
+		}
+		helpFlags = append(helpFlags, newHelpFlag)
+	}
+	return helpFlags
+}
+
+// builtinHelpSubcommands returns the built-in subcommands that are enabled
+// as help subcommands
+func (p *Parser) builtinHelpSubcommands() []HelpSubcommand {
+	var builtinCommands []HelpSubcommand
+	if p.hasHelpCommand() {
+		builtinCommands = append(builtinCommands, HelpSubcommand{
+			LongName:    helpCommandName,
+			Description: p.Message(MessageHelpCommand),
+			Position:    1,
+		})
+	}
+	if p.hasVersionCommand() {
+		builtinCommands = append(builtinCommands, HelpSubcommand{
+			LongName:    versionCommandName,
+			Description: p.Message(MessageVersionCommand),
+			Position:    1,
+		})
+	}
+	return builtinCommands
+}
+
+// makeHelpSubcommandTree converts the subcommands nested under a subcommand
+// into help subcommands in depth first order, skipping hidden subcommands
+// along with their descendants.  The built-in subcommands are listed last.
+func (p *Parser) makeHelpSubcommandTree(sc *Subcommand, builtinCommands []HelpSubcommand) []HelpSubcommand {
+	var tree []HelpSubcommand
+	var addSubcommands func(sc *Subcommand, depth int)
+	addSubcommands = func(sc *Subcommand, depth int) {
+		for _, cmd := range sc.Subcommands {
+			if cmd.Hidden {
+				continue
+			}
+			tree = append(tree, HelpSubcommand{
+				ShortName:   cmd.ShortName,
+				LongName:    cmd.Name,
+				Description: cmd.Description,
+				Position:    cmd.Position,
+				Category:    cmd.Category,
+				Depth:       depth,
+				Indent:      strings.Repeat("  ", depth),
+			})
+			addSubcommands(cmd, depth+1)
+		}
+	}
+	addSubcommands(sc, 0)
+	tree = append(tree, builtinCommands...)
+
+	// descriptions line up after the longest name, including its indent,
+	// short name and position
+	var maxLength int
+	for _, cmd := range tree {
+		if length := displayWidth(cmd.treeLabel(p)); length > maxLength {
+			maxLength = length
+		}
+	}
+	for i := range tree {
+		tree[i].Spacer = makeSpacer(tree[i].treeLabel(p), maxLength)
+	}
+	return tree
+}
+
+// treeLabel returns the subcommand as it is displayed in the subcommand tree
+// of the default help template. ex)   nodes (n)
+func (cmd HelpSubcommand) treeLabel(p *Parser) string {
+	label := cmd.Indent + cmd.LongName
+	if cmd.ShortName != "" {
+		label = label + " (" + cmd.ShortName + ")"
+	}
+	if cmd.Position > 1 {
+		label = label + "  (" + p.Message(MessagePosition, "position", strconv.Itoa(cmd.Position)) + ")"
+	}
+	return label
+}
+
+// groupByCategory groups the subcommands and the specified flags into
+// sections by their category.  Uncategorized subcommands and flags come
+// first, followed by each category in the order it first appears.
+func (h *Help) groupByCategory(p *Parser, flags []HelpFlag) {
+	h.SubcommandGroups = nil
+	for _, category := range helpCategories(len(h.Subcommands), func(i int) string { return h.Subcommands[i].Category }) {
+		group := HelpSubcommandGroup{Title: category}
+		if category == "" {
+			group.Title = p.Message(MessageSubcommandsTitle)
+		}
+		for _, cmd := range h.Subcommands {
+			if cmd.Category == category {
+				group.Subcommands = append(group.Subcommands, cmd)
+			}
+		}
+		h.SubcommandGroups = append(h.SubcommandGroups, group)
+	}
+
+	h.FlagGroups = nil
+	for _, category := range helpCategories(len(flags), func(i int) string { return flags[i].Category }) {
+		group := HelpFlagGroup{Title: category}
+		if category == "" {
+			group.Title = p.Message(MessageFlagsTitle)
+		}
+		for _, f := range flags {
+			if f.Category == category {
+				group.Flags = append(group.Flags, f)
+			}
 		}
-		h.AddFlagToHelp(newHelpFlag)
+		h.FlagGroups = append(h.FlagGroups, group)
 	}
 }
 
+// helpCategories returns the distinct categories of n items, with the empty
+// category first when any item is uncategorized and the rest in the order
+// they first appear
+func helpCategories(n int, category func(i int) string) []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		if category(i) == "" {
+			seen[""] = true
+			categories = append(categories, "")
+			break
+		}
+	}
+	for i := 0; i < n; i++ {
+		if c := category(i); !seen[c] {
+			seen[c] = true
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
+
 // AddFlagToHelp adds a flag to help output if it does not exist
 func (h *Help) AddFlagToHelp(f HelpFlag) {
-	for _, existingFlag := range h.Flags {
+	if containsHelpFlag(h.Flags, f) {
+		return
+	}
+	h.Flags = append(h.Flags, f)
+}
+
+// containsHelpFlag determines if a help flag with the same short or long
+// name as f is in the slice of help flags
+func containsHelpFlag(flags []HelpFlag, f HelpFlag) bool {
+	for _, existingFlag := range flags {
 		if len(existingFlag.ShortName) > 0 && existingFlag.ShortName == f.ShortName {
-			return
+			return true
 		}
 		if len(existingFlag.LongName) > 0 && existingFlag.LongName == f.LongName {
-			return
+			return true
 		}
 	}
-	h.Flags = append(h.Flags, f)
+	return false
 }
 
 // getLongestNameLength takes a slice of any supported flag and returns the length of the longest of their names
@@ -262,11 +611,11 @@ func getLongestNameLength(slice interface{}, min int) int {
 		case *Flag:
 			name = t.LongName
 		case *PositionalValue:
-			name = t.Name
+			name = t.helpName()
 		default:
 			log.Panicf("Unexpected type %T found in slice passed to getLongestNameLength(). Possible types: *Subcommand, *Flag, *PositionalValue", t)
 		}
-		length := len(name)
+		length := displayWidth(name)
 		if length > maxLength {
 			maxLength = length
 		}
@@ -275,10 +624,45 @@ func getLongestNameLength(slice interface{}, min int) int {
 	return maxLength
 }
 
+// getLongestFlagLabelLength returns the display width of the longest label
+// of the flags as help output displays them, or min if it is longer
+func getLongestFlagLabelLength(flags []*Flag, min int) int {
+	maxLength := min
+	for _, f := range flags {
+		if length := displayWidth(f.helpLabel()); length > maxLength {
+			maxLength = length
+		}
+	}
+	return maxLength
+}
+
+// flagHelpLabel returns a flag as the default help template displays it.
+// Flags without a short name are indented to line up with those that have
+// one. ex) -p --port int or    --color[=WHEN]
+func flagHelpLabel(shortName string, longName string, valueName string, optionalValue bool) string {
+	label := "   "
+	if shortName != "" {
+		label = "-" + shortName
+		if longName != "" {
+			label = label + " "
+		}
+	}
+	if longName != "" {
+		label = label + "--" + longName
+	}
+	if optionalValue {
+		return label + "[=" + valueName + "]"
+	}
+	if valueName != "" {
+		return label + " " + valueName
+	}
+	return label
+}
+
 // makeSpacer creates a string of whitespaces, with a length of the given
-// maxLength minus the length of the given name
+// maxLength minus the display width of the given name
 func makeSpacer(name string, maxLength int) string {
-	length := maxLength - utf8.RuneCountInString(name)
+	length := maxLength - displayWidth(name)
 	if length < 0 {
 		length = 0
 	}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/diegosz/flaggy"
+	"github.com/diegosz/flaggy/flaggytest"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -113,17 +114,58 @@ func TestHelpOutput(t *testing.T) {
 	}
 	got := strings.Split(string(buf[:n]), "\n")
 	want := []string{
-		"subcommandB - Subcommand B is a command that does other stuff",
+		"testCommand subcommandA subcommandB - Subcommand B is a command that does other stuff",
+		"",
+		"  Usage:",
+		"    testCommand subcommandA subcommandB",
+		"",
+		"  Flags: ",
+		"       --version                 Displays the program version string.",
+		"    -h --help                    Displays help with available flag, subcommand, and positional value parameters.",
+		"",
+		"  Global Flags: ",
+		"    -s --stringFlag string       This is a test string flag that does some stringy string stuff. (default: defaultStringHere)",
+		"    -i --intFlg int              This is a test int flag that does some interesting int stuff. (default: 0)",
+		"    -b --boolFlag                This is a test bool flag that does some booly bool stuff.",
+		"    -d --durationFlag duration   This is a test duration flag that does some untimely stuff. (default: 0s)",
+		"",
+		"This is a help message on exit",
+		"",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelpOutputOptionalValue(t *testing.T) {
+	p := flaggy.NewParser("TestHelpOutputOptionalValue")
+	var color string
+	p.OptionalString(&color, "", "color", "always", "Colorize the output.")
+	p.Flags[0].ValueName = "WHEN"
+
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: error: %s", err)
+	}
+	p.Output = wr
+
+	p.ShowHelp()
+
+	buf := make([]byte, 1024)
+	n, err := rd.Read(buf)
+	if err != nil {
+		t.Fatalf("read: error: %s", err)
+	}
+	got := strings.Split(string(buf[:n]), "\n")
+	want := []string{
+		"",
 		"",
 		"  Flags: ",
 		"       --version        Displays the program version string.",
 		"    -h --help           Displays help with available flag, subcommand, and positional value parameters.",
-		"    -s --stringFlag     This is a test string flag that does some stringy string stuff. (default: defaultStringHere)",
-		"    -i --intFlg         This is a test int flag that does some interesting int stuff. (default: 0)",
-		"    -b --boolFlag       This is a test bool flag that does some booly bool stuff.",
-		"    -d --durationFlag   This is a test duration flag that does some untimely stuff. (default: 0s)",
+		"       --color[=WHEN]   Colorize the output.",
 		"",
-		"This is a help message on exit",
 		"",
 	}
 
@@ -131,3 +173,374 @@ func TestHelpOutput(t *testing.T) {
 		t.Errorf("help mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestHelpOutputShortOnlyFlags(t *testing.T) {
+	p := flaggy.NewParser("TestHelpOutputShortOnlyFlags")
+	p.HelpWidth = 60
+	var port int
+	var color, name string
+	var verbose bool
+	p.Int(&port, "p", "", "The port to listen on, which must not be in use by another program.")
+	p.OptionalString(&color, "c", "", "always", "Colorize the output.")
+	p.Bool(&verbose, "v", "", "Verbose output.")
+	p.String(&name, "n", "name", "The name.")
+
+	got := strings.Split(flaggytest.Run(p, "--help").Stderr, "\n")
+	want := []string{
+		"TestHelpOutputShortOnlyFlags",
+		"",
+		"  Flags: ",
+		"       --version       Displays the program version string.",
+		"    -h --help          Displays help with available flag,",
+		"                       subcommand, and positional value",
+		"                       parameters.",
+		"    -p int             The port to listen on, which must not",
+		"                       be in use by another program.",
+		"                       (default: 0)",
+		"    -c[=string]        Colorize the output.",
+		"    -v                 Verbose output.",
+		"    -n --name string   The name.",
+		"",
+		"",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelpOutputCustomTemplateSpacer(t *testing.T) {
+	p := flaggy.NewParser("TestHelpOutputCustomTemplateSpacer")
+	p.ShowVersionWithVersionFlag = false
+	var port int
+	var name string
+	p.Int(&port, "p", "port", "The port.")
+	p.String(&name, "", "name", "The name.")
+	err := p.SetHelpTemplate(`{{range .Flags}}{{if .ShortName}}-{{.ShortName}} {{else}}   {{end}}--{{.LongName}}   {{.Spacer}}{{.Description}}
+{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Split(flaggytest.Run(p, "--help").Stderr, "\n")
+	want := []string{
+		"-h --help      Displays help with available flag, subcommand, and positional value parameters.",
+		"-p --port      The port.",
+		"   --name      The name.",
+		"",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelpOutputVariadicPositional(t *testing.T) {
+	p := flaggy.NewParser("cp")
+	p.ShowHelpWithHFlag = false
+	p.ShowVersionWithVersionFlag = false
+	var sources []string
+	var destination string
+	p.AddVariadicPositionalValue(&sources, "SRC", 1, 1, 0, "Source files.")
+	p.AddPositionalValue(&destination, "DST", 2, true, "Destination.")
+
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: error: %s", err)
+	}
+	p.Output = wr
+
+	if err := p.ParseArgs([]string{"a.txt", "dir"}); err != nil {
+		t.Fatalf("got: %s; want: no error", err)
+	}
+	p.ShowHelp()
+
+	buf := make([]byte, 1024)
+	n, err := rd.Read(buf)
+	if err != nil {
+		t.Fatalf("read: error: %s", err)
+	}
+	got := strings.Split(string(buf[:n]), "\n")
+	want := []string{
+		"cp",
+		"",
+		"  Usage:",
+		"    cp [SRC...] [DST]",
+		"",
+		"  Positional Variables: ",
+		"    SRC...   Source files. (Required)",
+		"    DST      Destination. (Required)",
+		"",
+		"",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelpOutputWrapped(t *testing.T) {
+	p := flaggy.NewParser("wrap")
+	p.ShowHelpWithHFlag = false
+	p.ShowVersionWithVersionFlag = false
+	p.HelpWidth = 50
+	var name, city string
+	p.String(&name, "n", "name", "The name to greet, which is printed at the start of every line of output.")
+	p.String(&city, "", "都市", "The city.")
+
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: error: %s", err)
+	}
+	p.Output = wr
+
+	p.ShowHelp()
+
+	buf := make([]byte, 1024)
+	n, err := rd.Read(buf)
+	if err != nil {
+		t.Fatalf("read: error: %s", err)
+	}
+	got := strings.Split(string(buf[:n]), "\n")
+	want := []string{
+		"",
+		"",
+		"  Flags: ",
+		"    -n --name string   The name to greet, which is",
+		"                       printed at the start of",
+		"                       every line of output.",
+		"       --都市 string   The city.",
+		"",
+		"",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelpOutputCategories(t *testing.T) {
+	p := flaggy.NewParser("deploy")
+	p.ShowVersionWithVersionFlag = false
+	var region, zone string
+	var dryRun bool
+	p.String(&region, "r", "region", "The region to deploy to.")
+	p.Bool(&dryRun, "", "dry-run", "Prints changes without applying them.")
+	p.String(&zone, "z", "zone", "The zone to deploy to.")
+	p.SetFlagCategory("region", "Placement")
+	p.SetFlagCategory("z", "Placement")
+
+	status := flaggy.NewSubcommand("status")
+	status.Description = "Shows the deployment status."
+	rollback := flaggy.NewSubcommand("rollback")
+	rollback.Description = "Rolls back the last deployment."
+	rollback.Category = "Recovery"
+	apply := flaggy.NewSubcommand("apply")
+	apply.Description = "Applies the deployment."
+	p.AttachSubcommand(status, 1)
+	p.AttachSubcommand(rollback, 1)
+	p.AttachSubcommand(apply, 1)
+	if err := p.ParseArgs([]string{}); err != nil {
+		t.Fatalf("parse: error: %s", err)
+	}
+
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: error: %s", err)
+	}
+	p.Output = wr
+
+	p.ShowHelp()
+
+	buf := make([]byte, 1024)
+	n, err := rd.Read(buf)
+	if err != nil {
+		t.Fatalf("read: error: %s", err)
+	}
+	got := strings.Split(string(buf[:n]), "\n")
+	want := []string{
+		"deploy",
+		"",
+		"  Usage:",
+		"    deploy [status|rollback|apply]",
+		"",
+		"  Subcommands: ",
+		"    status     Shows the deployment status.",
+		"    apply      Applies the deployment.",
+		"",
+		"  Recovery: ",
+		"    rollback   Rolls back the last deployment.",
+		"",
+		"  Flags: ",
+		"    -h --help            Displays help with available flag, subcommand, and positional value parameters.",
+		"       --dry-run         Prints changes without applying them.",
+		"",
+		"  Placement: ",
+		"    -r --region string   The region to deploy to.",
+		"    -z --zone string     The zone to deploy to.",
+		"",
+		"",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelpOutputExamples(t *testing.T) {
+	p := flaggy.NewParser("ourtool")
+	p.ShowHelpWithHFlag = false
+	p.ShowVersionWithVersionFlag = false
+	p.HelpWidth = 40
+	p.AddExample("ourtool --verbose", "")
+	p.AddExample("ourtool @args.txt", "Reads more args from args.txt, one per line or separated by spaces.")
+	if err := p.ParseArgs([]string{}); err != nil {
+		t.Fatalf("parse: error: %s", err)
+	}
+
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: error: %s", err)
+	}
+	p.Output = wr
+
+	p.ShowHelp()
+
+	buf := make([]byte, 1024)
+	n, err := rd.Read(buf)
+	if err != nil {
+		t.Fatalf("read: error: %s", err)
+	}
+	got := strings.Split(string(buf[:n]), "\n")
+	want := []string{
+		"ourtool",
+		"",
+		"  Examples: ",
+		"    ourtool --verbose",
+		"    ourtool @args.txt",
+		"      Reads more args from args.txt, one",
+		"      per line or separated by spaces.",
+		"",
+		"",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelpOutputSubcommandTree(t *testing.T) {
+	p := flaggy.NewParser("mytool")
+	p.ShowVersionWithVersionFlag = false
+	p.ShowHelpAllWithHelpAllFlag = true
+	p.HelpWidth = -1
+	cluster := flaggy.NewSubcommand("cluster")
+	cluster.ShortName = "c"
+	cluster.Description = "Manages clusters."
+	nodes := flaggy.NewSubcommand("nodes")
+	nodes.Description = "Manages the nodes of a cluster."
+	drain := flaggy.NewSubcommand("drain")
+	drain.Description = "Drains a node."
+	internal := flaggy.NewSubcommand("internal")
+	internal.Hidden = true
+	internal.AttachSubcommand(flaggy.NewSubcommand("secret"), 1)
+	status := flaggy.NewSubcommand("status")
+	nodes.AttachSubcommand(drain, 1)
+	cluster.AttachSubcommand(nodes, 1)
+	cluster.AttachSubcommand(internal, 1)
+	p.AttachSubcommand(cluster, 1)
+	p.AttachSubcommand(status, 2)
+
+	result := flaggytest.Run(p, "--help-all")
+	got := strings.Split(result.Stderr, "\n")
+	want := []string{
+		"mytool",
+		"",
+		"  Usage:",
+		"    mytool [cluster] [status]",
+		"",
+		"  Subcommands: ",
+		"    cluster (c)            Manages clusters.",
+		"      nodes                Manages the nodes of a cluster.",
+		"        drain              Drains a node.",
+		"    status  (position 2)",
+		"",
+		"  Flags: ",
+		"    -h --help       Displays help with available flag, subcommand, and positional value parameters.",
+		"       --help-all   Displays help with every nested subcommand.",
+		"",
+		"",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+
+	result = flaggytest.Run(p, "cluster", "--help")
+	if strings.Contains(result.Stderr, "drain") {
+		t.Fatal("expected only direct subcommands without --help-all, got:", result.Stderr)
+	}
+}
+
+func TestHelpOutputParentChain(t *testing.T) {
+	p := flaggy.NewParser("app")
+	p.ShowVersionWithVersionFlag = false
+	p.HelpWidth = -1
+	var region, context, force string
+	var verbose bool
+	p.AddPositionalValue(&region, "region", 1, false, "The region.")
+	p.Bool(&verbose, "v", "verbose", "Verbose output.")
+	cluster := flaggy.NewSubcommand("cluster")
+	cluster.String(&context, "c", "context", "The cluster context.")
+	cluster.SetFlagScope("context", flaggy.PersistentScope)
+	node := flaggy.NewSubcommand("node")
+	drain := flaggy.NewSubcommand("drain")
+	drain.Description = "Drains a node."
+	drain.String(&force, "f", "force", "Forces the drain.")
+	node.AttachSubcommand(drain, 1)
+	cluster.AttachSubcommand(node, 1)
+	p.AttachSubcommand(cluster, 2)
+
+	result := flaggytest.Run(p, "eu", "cluster", "node", "drain", "-h")
+	got := strings.Split(result.Stderr, "\n")
+	want := []string{
+		"app cluster node drain - Drains a node.",
+		"",
+		"  Usage:",
+		"    app [region] cluster node drain",
+		"",
+		"  Flags: ",
+		"    -h --help             Displays help with available flag, subcommand, and positional value parameters.",
+		"    -f --force string     Forces the drain.",
+		"",
+		"  Flags inherited from app cluster: ",
+		"    -c --context string   The cluster context.",
+		"",
+		"  Global Flags: ",
+		"    -v --verbose          Verbose output.",
+		"",
+		"",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("help mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelpFlagsIncludeInheritedFlags(t *testing.T) {
+	p := flaggy.NewParser("app")
+	p.ShowVersionWithVersionFlag = false
+	var verbose, force bool
+	p.Bool(&verbose, "v", "verbose", "Verbose output.")
+	deploy := flaggy.NewSubcommand("deploy")
+	deploy.Bool(&force, "f", "force", "Force the deploy.")
+	p.AttachSubcommand(deploy, 1)
+	err := p.SetHelpTemplate(`{{range .Flags}}{{.LongName}} {{end}}| {{range .FlagGroups}}{{range .Flags}}{{.LongName}} {{end}}{{end}}| {{range .GlobalFlags}}{{.LongName}} {{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := flaggytest.Run(p, "deploy", "--help").Stderr
+	want := "help force verbose | help force | verbose "
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
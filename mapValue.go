@@ -0,0 +1,113 @@
+package flaggy
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MapKeyPolicy determines what happens when a key is supplied more than once
+// to a map flag during a single parse.
+type MapKeyPolicy int
+
+const (
+	// MapKeyError returns an error when a key is supplied more than once
+	MapKeyError MapKeyPolicy = iota
+	// MapKeyLastWins keeps the value from the last occurrence of a key
+	MapKeyLastWins
+	// MapKeyCollect appends every value for a key.  The map's values must be
+	// a slice type, such as map[string][]string.
+	MapKeyCollect
+)
+
+// isMapFlag indicates that the assignment var is a pointer to a map with
+// string keys
+func isMapFlag(assignmentVar interface{}) bool {
+	t := reflect.TypeOf(assignmentVar)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return false
+	}
+	return t.Elem().Kind() == reflect.Map && t.Elem().Key().Kind() == reflect.String
+}
+
+// assignMapValue parses comma separated key=value pairs and assigns them to
+// the flag's map assignment var.  Each value is parsed as if it was passed to
+// a flag of the map's value type.
+func (f *Flag) assignMapValue(value string) error {
+	m := reflect.ValueOf(f.AssignmentVar).Elem()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	valueType := m.Type().Elem()
+
+	if f.MapKeyPolicy == MapKeyCollect && valueType.Kind() != reflect.Slice {
+		return errors.New("Map flag " + f.displayName() + " collects duplicate keys but its values are not a slice type")
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return newMessageError(MessageMapPair, "flag", f.displayName(), "pair", pair)
+		}
+		key, val := kv[0], kv[1]
+
+		if f.mapKeysSet == nil {
+			f.mapKeysSet = make(map[string]bool)
+		}
+		duplicate := f.mapKeysSet[key]
+		f.mapKeysSet[key] = true
+		if duplicate && f.MapKeyPolicy == MapKeyError {
+			return newMessageError(MessageMapDuplicateKey, "key", key, "flag", f.displayName())
+		}
+
+		// parse the value by assigning it to a flag of the map's value type.
+		// Slices start from the existing value only when collecting so that
+		// defaults are replaced by the first value supplied.
+		elem := reflect.New(valueType)
+		if duplicate && f.MapKeyPolicy == MapKeyCollect {
+			elem.Elem().Set(m.MapIndex(reflect.ValueOf(key)))
+		}
+		valueFlag := Flag{
+			LongName:      f.LongName,
+			ShortName:     f.ShortName,
+			AssignmentVar: elem.Interface(),
+			parsed:        true,
+		}
+		if err := valueFlag.identifyAndAssignValue(val); err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(key), elem.Elem())
+	}
+
+	return nil
+}
+
+// mapValueAsString returns the flag's map assignment var as comma separated
+// key=value pairs sorted by key, so that help output is deterministic.
+func (f *Flag) mapValueAsString() (string, error) {
+	m := reflect.ValueOf(f.AssignmentVar).Elem()
+
+	var keys []string
+	for _, k := range m.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		elem := reflect.New(m.Type().Elem())
+		elem.Elem().Set(m.MapIndex(reflect.ValueOf(k)))
+		valueFlag := Flag{
+			LongName:      f.LongName,
+			ShortName:     f.ShortName,
+			AssignmentVar: elem.Interface(),
+		}
+		v, err := valueFlag.returnAssignmentVarValueAsString()
+		if err != nil {
+			return "", err
+		}
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ","), nil
+}
@@ -0,0 +1,236 @@
+package flaggy
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// MessageKey identifies a built-in message in a Catalog
+type MessageKey string
+
+// The keys of the built-in messages.  The parameters each message is
+// formatted with are listed next to its key.
+const (
+	MessageUnknownArguments        MessageKey = "error.unknownArguments"        // {args}
+	MessageMissingFlagValue        MessageKey = "error.missingFlagValue"        // {flag}
+	MessageNoSubcommandAtPosition  MessageKey = "error.noSubcommandAtPosition"  // {subcommand} {position}
+	MessageAvailableSubcommands    MessageKey = "error.availableSubcommands"    // {subcommands}
+	MessageUnexpectedArgument      MessageKey = "error.unexpectedArgument"      // {arg}
+	MessageMissingGlobalPositional MessageKey = "error.missingGlobalPositional" // {name} {position}
+	MessageMissingPositional       MessageKey = "error.missingPositional"       // {subcommand} {name} {position}
+	MessageTooFewVariadicValues    MessageKey = "error.tooFewVariadicValues"    // {subcommand} {name} {min} {count}
+	MessageUnknownHelpTopic        MessageKey = "error.unknownHelpTopic"        // {path}
+	MessageInvalidFlagValue        MessageKey = "error.invalidFlagValue"        // {value} {name} {error}
+	MessageInvalidPositionalValue  MessageKey = "error.invalidPositionalValue"  // {value} {name} {error}
+	MessageMapPair                 MessageKey = "error.mapPair"                 // {flag} {pair}
+	MessageMapDuplicateKey         MessageKey = "error.mapDuplicateKey"         // {key} {flag}
+	MessageResponseFileLoop        MessageKey = "error.responseFileLoop"        // {path} {chain}
+	MessageResponseFileRead        MessageKey = "error.responseFileRead"        // {error}
+	MessageResponseFileParse       MessageKey = "error.responseFileParse"       // {path} {error}
+	MessageResponseFileBackslash   MessageKey = "error.responseFileBackslash"
+	MessageResponseFileQuote       MessageKey = "error.responseFileQuote" // {quote}
+	MessageValidateMin             MessageKey = "validate.min"            // {min}
+	MessageValidateMax             MessageKey = "validate.max"            // {max}
+	MessageValidateRange           MessageKey = "validate.range"          // {min} {max}
+	MessageValidateMinLength       MessageKey = "validate.minLength"      // {min}
+	MessageValidateMaxLength       MessageKey = "validate.maxLength"      // {max}
+	MessageValidateMatch           MessageKey = "validate.match"          // {pattern}
+	MessageValidateNonEmpty        MessageKey = "validate.nonEmpty"
+	MessageValidateNoEmptyValues   MessageKey = "validate.noEmptyValues"
+	MessageVersionFlag             MessageKey = "description.versionFlag"
+	MessageHelpFlag                MessageKey = "description.helpFlag"
+	MessageHelpAllFlag             MessageKey = "description.helpAllFlag"
+	MessageHelpCommand             MessageKey = "description.helpCommand"
+	MessageVersionCommand          MessageKey = "description.versionCommand"
+	MessageUsageTitle              MessageKey = "title.usage"
+	MessagePositionalsTitle        MessageKey = "title.positionals"
+	MessageSubcommandsTitle        MessageKey = "title.subcommands"
+	MessageHelpTopicsTitle         MessageKey = "title.helpTopics"
+	MessageFlagsTitle              MessageKey = "title.flags"
+	MessageGlobalFlagsTitle        MessageKey = "title.globalFlags"
+	MessageInheritedFlagsTitle     MessageKey = "title.inheritedFlags" // {command}
+	MessageExamplesTitle           MessageKey = "title.examples"
+	MessageRequired                MessageKey = "label.required"
+	MessageDefault                 MessageKey = "label.default"  // {value}
+	MessagePosition                MessageKey = "label.position" // {position}
+)
+
+// Catalog maps message keys to message formats.  Formats name their
+// parameters in braces, such as {name}, so a translation can place them
+// wherever its language needs them.  Panics about invalid parser
+// configuration and errors from parsing values, such as strconv errors for
+// int flags, are not in the catalog.
+type Catalog map[MessageKey]string
+
+// DefaultCatalog is the English catalog.  Messages missing from a parser's
+// catalog are taken from it.
+var DefaultCatalog = Catalog{
+	MessageUnknownArguments:        "Unknown arguments supplied: {args}",
+	MessageMissingFlagValue:        "Expected a following arg for flag {flag}, but it did not exist.",
+	MessageNoSubcommandAtPosition:  "{subcommand}: No subcommand or positional value found at position {position}.",
+	MessageAvailableSubcommands:    "Available subcommands: {subcommands}",
+	MessageUnexpectedArgument:      "Unexpected argument: {arg}",
+	MessageMissingGlobalPositional: "Required global positional variable {name} not found at position {position}",
+	MessageMissingPositional:       "Required positional of subcommand {subcommand} named {name} not found at position {position}",
+	MessageTooFewVariadicValues:    "Variadic positional of subcommand {subcommand} named {name} requires at least {min} values but got {count}",
+	MessageUnknownHelpTopic:        "Unknown help topic or subcommand: {path}",
+	MessageInvalidFlagValue:        "Invalid value \"{value}\" for flag {name}: {error}",
+	MessageInvalidPositionalValue:  "Invalid value \"{value}\" for positional {name}: {error}",
+	MessageMapPair:                 "Expected key=value for map flag {flag} but got: {pair}",
+	MessageMapDuplicateKey:         "Key {key} supplied more than once for map flag {flag}",
+	MessageResponseFileLoop:        "Response file {path} includes itself: {chain}",
+	MessageResponseFileRead:        "Unable to read response file: {error}",
+	MessageResponseFileParse:       "Unable to parse response file {path}: {error}",
+	MessageResponseFileBackslash:   "unexpected end of file after backslash",
+	MessageResponseFileQuote:       "unterminated quote {quote}",
+	MessageValidateMin:             "must be at least {min}",
+	MessageValidateMax:             "must be at most {max}",
+	MessageValidateRange:           "must be between {min} and {max}",
+	MessageValidateMinLength:       "must be at least {min} characters long",
+	MessageValidateMaxLength:       "must be at most {max} characters long",
+	MessageValidateMatch:           "must match {pattern}",
+	MessageValidateNonEmpty:        "must not be empty",
+	MessageValidateNoEmptyValues:   "must not contain empty values",
+	MessageVersionFlag:             "Displays the program version string.",
+	MessageHelpFlag:                "Displays help with available flag, subcommand, and positional value parameters.",
+	MessageHelpAllFlag:             "Displays help with every nested subcommand.",
+	MessageHelpCommand:             "Displays help for a subcommand or help topic.",
+	MessageVersionCommand:          "Displays the program version.",
+	MessageUsageTitle:              "Usage",
+	MessagePositionalsTitle:        "Positional Variables",
+	MessageSubcommandsTitle:        "Subcommands",
+	MessageHelpTopicsTitle:         "Help Topics",
+	MessageFlagsTitle:              "Flags",
+	MessageGlobalFlagsTitle:        "Global Flags",
+	MessageInheritedFlagsTitle:     "Flags inherited from {command}",
+	MessageExamplesTitle:           "Examples",
+	MessageRequired:                "Required",
+	MessageDefault:                 "default: {value}",
+	MessagePosition:                "position {position}",
+}
+
+// messageTemplateFuncs are the message functions available to help templates.
+// They format messages from the default catalog until a parser's catalog is
+// applied.
+var messageTemplateFuncs = template.FuncMap{
+	"msg": func(key string, params ...string) string {
+		return DefaultCatalog.format(MessageKey(key), params...)
+	},
+}
+
+// LoadCatalog reads a catalog from a JSON object of message keys and formats.
+// ex) {"title.flags": "Optionen", "error.unexpectedArgument": "Unerwartetes Argument: {arg}"}
+func LoadCatalog(r io.Reader) (Catalog, error) {
+	catalog := Catalog{}
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// message formats the message with the key from this catalog, or the
+// default catalog when this catalog does not have it
+func (c Catalog) message(key MessageKey, params ...string) string {
+	if _, ok := c[key]; ok {
+		return c.format(key, params...)
+	}
+	return DefaultCatalog.format(key, params...)
+}
+
+// format formats the message with the key, replacing each named parameter.
+// params are pairs of parameter names and values.  The key itself is
+// returned when the catalog has no such message.
+func (c Catalog) format(key MessageKey, params ...string) string {
+	format, ok := c[key]
+	if !ok {
+		return string(key)
+	}
+	var oldnew []string
+	for i := 0; i+1 < len(params); i += 2 {
+		oldnew = append(oldnew, "{"+params[i]+"}", params[i+1])
+	}
+	return strings.NewReplacer(oldnew...).Replace(format)
+}
+
+// Message formats the message with the key from this parser's catalog, or
+// the default catalog when the parser's catalog does not have it.  params
+// are pairs of parameter names and values.
+// ex) p.Message(MessageUnexpectedArgument, "arg", "foo")
+func (p *Parser) Message(key MessageKey, params ...string) string {
+	return p.Messages.message(key, params...)
+}
+
+// LoadMessages reads a catalog of translated messages from a JSON object and
+// adds its messages to this parser's catalog
+func (p *Parser) LoadMessages(r io.Reader) error {
+	catalog, err := LoadCatalog(r)
+	if err != nil {
+		return err
+	}
+	// the catalog is replaced rather than changed, as it may be shared with
+	// clones of this parser
+	merged := Catalog{}
+	for key, format := range p.Messages {
+		merged[key] = format
+	}
+	for key, format := range catalog {
+		merged[key] = format
+	}
+	p.Messages = merged
+	return nil
+}
+
+// messageTemplateFuncs returns the message functions that format messages from
+// this parser's catalog
+func (p *Parser) messageTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"msg": func(key string, params ...string) string {
+			return p.Message(MessageKey(key), params...)
+		},
+	}
+}
+
+// MessageError is an error with a message from a catalog, such as the
+// errors of built-in validators and invalid values.  Errors returned by
+// ParseArgs are formatted with the parser's catalog.
+type MessageError struct {
+	Key     MessageKey
+	Params  []string // pairs of parameter names and values
+	Err     error    // the cause, formatted as the {error} parameter
+	catalog Catalog
+}
+
+// newMessageError creates an error with the message with the key
+func newMessageError(key MessageKey, params ...string) *MessageError {
+	return &MessageError{Key: key, Params: params}
+}
+
+// Error formats the message of the error
+func (e *MessageError) Error() string {
+	params := e.Params
+	if e.Err != nil {
+		params = append(append([]string(nil), params...), "error", e.Err.Error())
+	}
+	return e.catalog.message(e.Key, params...)
+}
+
+// Unwrap returns the cause of the error
+func (e *MessageError) Unwrap() error {
+	return e.Err
+}
+
+// localizeError makes the error, and each error it wraps, format its
+// message with this parser's catalog
+func (p *Parser) localizeError(err error) error {
+	for cause := err; cause != nil; {
+		e, ok := cause.(*MessageError)
+		if !ok {
+			break
+		}
+		e.catalog = p.Messages
+		cause = e.Err
+	}
+	return err
+}
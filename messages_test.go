@@ -0,0 +1,95 @@
+package flaggy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+	"github.com/diegosz/flaggy/flaggytest"
+)
+
+func TestMessages(t *testing.T) {
+	p := flaggy.NewParser("outil")
+	p.HelpWidth = -1
+	err := p.LoadMessages(strings.NewReader(`{
+		"title.usage": "Utilisation",
+		"title.flags": "Options",
+		"title.subcommands": "Sous-commandes",
+		"description.helpFlag": "Affiche l'aide.",
+		"label.default": "défaut : {value}",
+		"error.missingPositional": "Valeur {name} manquante à la position {position} de {subcommand}"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "x"
+	p.String(&name, "n", "name", "Un nom.")
+	deploy := flaggy.NewSubcommand("deploy")
+	var target string
+	deploy.AddPositionalValue(&target, "target", 1, true, "La cible.")
+	p.AttachSubcommand(deploy, 1)
+
+	result := flaggytest.Run(p.Clone(), "--help")
+	for _, want := range []string{
+		"  Utilisation:\n",
+		"  Sous-commandes: \n    deploy\n",
+		"  Options: \n",
+		"Affiche l'aide.",
+		"Un nom. (défaut : x)",
+		"Displays the program version string.",
+	} {
+		if !strings.Contains(result.Stderr, want) {
+			t.Fatalf("expected help to contain %q, got:\n%s", want, result.Stderr)
+		}
+	}
+
+	result = flaggytest.Run(p, "deploy")
+	if result.ExitCode != 2 || !strings.Contains(result.Stderr, "Valeur target manquante à la position 1 de deploy") {
+		t.Fatal("expected a translated error, got:", result.ExitCode, result.Stderr)
+	}
+
+	if err := p.LoadMessages(strings.NewReader("not json")); err == nil {
+		t.Fatal("expected an error loading an invalid catalog")
+	}
+}
+
+func TestMessageDefaults(t *testing.T) {
+	p := flaggy.NewParser("test")
+	if got := p.Message(flaggy.MessageUnexpectedArgument, "arg", "{arg}"); got != "Unexpected argument: {arg}" {
+		t.Fatal("expected the default message, got:", got)
+	}
+	p.Messages = flaggy.Catalog{flaggy.MessageUnexpectedArgument: "{arg}?"}
+	if got := p.Message(flaggy.MessageUnexpectedArgument, "arg", "x"); got != "x?" {
+		t.Fatal("expected the parser's message, got:", got)
+	}
+	if got := p.Message("missing"); got != "missing" {
+		t.Fatal("expected the key of a missing message, got:", got)
+	}
+}
+
+func TestMessageErrors(t *testing.T) {
+	p := flaggy.NewParser("outil")
+	p.Messages = flaggy.Catalog{
+		flaggy.MessageInvalidFlagValue: "Valeur « {value} » invalide pour {name} : {error}",
+		flaggy.MessageValidateRange:    "doit être entre {min} et {max}",
+	}
+	var port int
+	p.Int(&port, "p", "port", "Le port.")
+	p.Validate("port", flaggy.Range(1, 10))
+	err := p.ParseArgs([]string{"--port", "20"})
+	if err == nil || err.Error() != "Valeur « 20 » invalide pour --port : doit être entre 1 et 10" {
+		t.Fatal("expected a translated validator error, got:", err)
+	}
+	if messageErr, ok := err.(*flaggy.MessageError); !ok || messageErr.Key != flaggy.MessageInvalidFlagValue {
+		t.Fatal("expected a message error, got:", err)
+	}
+
+	p = flaggy.NewParser("outil")
+	p.Messages = flaggy.Catalog{flaggy.MessageMapDuplicateKey: "Clé {key} répétée pour {flag}"}
+	var labels map[string]string
+	p.StringMap(&labels, "l", "label", "Les étiquettes.")
+	err = p.ParseArgs([]string{"-l", "a=1,a=2"})
+	if err == nil || err.Error() != "Clé a répétée pour --label" {
+		t.Fatal("expected a translated map error, got:", err)
+	}
+}
@@ -15,17 +15,37 @@ import (
 // parsing an entire set of subcommands and flags.
 type Parser struct {
 	Subcommand
-	Version                    string             // the optional version of the parser.
-	ShowHelpWithHFlag          bool               // display help when -h or --help passed
-	ShowVersionWithVersionFlag bool               // display the version when --version passed
-	ShowHelpOnUnexpected       bool               // display help when an unexpected flag or subcommand is passed
-	TrailingArguments          []string           // everything after a -- is placed here
-	HelpTemplate               *template.Template // template for Help output
-	trailingArgumentsExtracted bool               // indicates that trailing args have been parsed and should not be appended again
-	parsed                     bool               // indicates this parser has parsed
-	subcommandContext          *Subcommand        // points to the most specific subcommand being used
-	AllowReParse               bool               // indicates this parser could be re-parsed
-	Output                     io.Writer          // output writer for help and error messages, defaults to os.Stderr
+	Version                       string             // the optional version of the parser.
+	ShowHelpWithHFlag             bool               // display help when -h or --help passed
+	ShowHelpWithHelpCommand       bool               // display help when the help subcommand is used. ex) mytool help deploy
+	ShowHelpAllWithHelpAllFlag    bool               // display help with every nested subcommand when --help-all passed
+	ShowSubcommandTree            bool               // list every nested subcommand in help instead of only the direct subcommands
+	helpAllRequested              bool               // indicates --help-all was passed during the last parse
+	ShowVersionWithVersionFlag    bool               // display the version when --version passed. --version --output json displays it as JSON
+	ShowVersionWithVersionCommand bool               // display the version when the version subcommand is used
+	ShowHelpOnUnexpected          bool               // display help when an unexpected flag or subcommand is passed
+	TrailingArguments             []string           // everything after a -- is placed here
+	HelpTemplate                  *template.Template // template for Help output
+	VersionTemplate               *template.Template // template for version output, executed with a VersionInfo
+	trailingArgumentsExtracted    bool               // indicates that trailing args have been parsed and should not be appended again
+	parsed                        bool               // indicates this parser has parsed
+	subcommandContext             *Subcommand        // points to the most specific subcommand being used
+	AllowReParse                  bool               // indicates this parser could be re-parsed
+	Output                        io.Writer          // output writer for help and error messages, defaults to os.Stderr
+	Stdout                        io.Writer          // output writer for version output, defaults to os.Stdout
+	ExitFunc                      func(code int)     // called instead of os.Exit when set. Must not return, such as by panicking or calling runtime.Goexit
+	PanicInsteadOfExit            bool               // panics instead of calling os.Exit, used when running tests
+	DebugMode                     bool               // writes debug events to os.Stderr when there is no DebugLogger
+	DebugLogger                   DebugLogger        // receives structured debug events while parsing
+	globalDebugMode               bool               // the DebugMode package variable when it was last copied into this parser
+	globalPanicInsteadOfExit      bool               // the PanicInsteadOfExit package variable when it was last copied into this parser
+	ExpandResponseFiles           bool               // expands @path args into the args read from that file
+	AllowNegativeNumbers          bool               // treats args like -5 or -1h as values when no flag has that name
+	HelpWidth                     int                // the width help is wrapped to. 0 detects it from COLUMNS or the terminal, negative disables wrapping
+	Color                         ColorMode          // determines if help output is styled, detected from NO_COLOR, CLICOLOR_FORCE and the terminal by default
+	Theme                         *Theme             // the styles of help output, DefaultTheme when nil
+	HelpTopics                    []Topic            // help topics listed in help and shown with the help subcommand
+	Messages                      Catalog            // translations of built-in messages and help titles, DefaultCatalog is used for messages it does not have
 }
 
 // TrailingSubcommand returns the last and most specific subcommand invoked.
@@ -42,9 +62,16 @@ func NewParser(name string) *Parser {
 	p.ShowHelpOnUnexpected = true
 	p.ShowHelpWithHFlag = true
 	p.ShowVersionWithVersionFlag = true
+	p.AllowNegativeNumbers = true
+	p.DebugMode = DebugMode
+	p.PanicInsteadOfExit = PanicInsteadOfExit
+	p.globalDebugMode = DebugMode
+	p.globalPanicInsteadOfExit = PanicInsteadOfExit
 	p.SetHelpTemplate(DefaultHelpTemplate)
+	p.SetVersionTemplate(defaultVersionTemplate)
 	p.subcommandContext = &Subcommand{}
 	p.Output = os.Stderr
+	p.Stdout = os.Stdout
 	return p
 }
 
@@ -57,31 +84,143 @@ func (p *Parser) ParseArgs(args []string) error {
 		return errors.New("Parser.Parse() called twice on parser with name: " + " " + p.Name + " " + p.ShortName)
 	}
 	p.parsed = true
+	p.helpAllRequested = false
 
-	debugPrint("Kicking off parsing with args:", args)
+	// index all flags by name for this parse only
+	p.Subcommand.indexFlags()
+	defer p.Subcommand.dropFlagIndexes()
+
+	// remember the values of all assignment vars for Reset and Clone
+	p.Subcommand.snapshot()
+
+	// flags track if they were set and which map keys were set during a parse
+	for _, f := range collectAllNestedFlags(&p.Subcommand) {
+		f.set = false
+		f.rawValue = ""
+		f.mapKeysSet = nil
+	}
+
+	// expand response files before any flags are parsed
+	if p.ExpandResponseFiles {
+		var err error
+		args, err = expandResponseFiles(args)
+		if err != nil {
+			return p.localizeError(err)
+		}
+		p.debug("expanded response files", "args", args)
+	}
+
+	// args after the positionals of a subcommand that does not allow
+	// interspersed flags are passed through untouched
+	args, passthroughArgs, passthroughSC := p.splitNonInterspersedArgs(args)
+
+	p.debug("parsing args", "args", args)
 	err := p.parse(p, args, 0)
 	if err != nil {
-		return err
+		return p.localizeError(err)
+	}
+
+	if passthroughSC != nil {
+		p.debug("passed through args", "subcommand", passthroughSC.Name, "args", passthroughArgs)
+		if passthroughSC != &p.Subcommand {
+			passthroughSC.TrailingArguments = append(passthroughSC.TrailingArguments, passthroughArgs...)
+		}
+		p.TrailingArguments = append(p.TrailingArguments, passthroughArgs...)
 	}
 
 	// if we are set to crash on unexpected args, look for those here TODO
 	if p.ShowHelpOnUnexpected {
 		parsedValues := p.findAllParsedValues()
-		debugPrint("parsedValues:", parsedValues)
 		argsNotParsed := findArgsNotInParsedValues(args, parsedValues)
+		for _, a := range argsNotParsed {
+			p.debug("unknown argument", "arg", a)
+		}
 		if len(argsNotParsed) > 0 {
 			// flatten out unused args for our error message
 			var argsNotParsedFlat string
 			for _, a := range argsNotParsed {
 				argsNotParsedFlat = argsNotParsedFlat + " " + a
 			}
-			p.ShowHelpAndExit("Unknown arguments supplied: " + argsNotParsedFlat)
+			p.ShowHelpAndExit(p.Message(MessageUnknownArguments, "args", argsNotParsedFlat))
 		}
 	}
 
 	return nil
 }
 
+// splitNonInterspersedArgs walks the args to find the subcommand that will be
+// used.  If it has DisableInterspersed set, the args are split at the first
+// positional arg after its own positional values are satisfied.  The args to
+// parse, the args to pass through and the subcommand they belong to are
+// returned.  The subcommand is nil when no args are passed through.
+func (p *Parser) splitNonInterspersedArgs(args []string) ([]string, []string, *Subcommand) {
+	sc := &p.Subcommand
+	var positionalCount int
+	var skipNext bool
+	for i, a := range args {
+		if skipNext {
+			skipNext = false
+			continue
+		}
+
+		argType := determineArgType(a)
+		flagName := parseFlagToName(a)
+		if argType == argIsFlagWithSpace && p.AllowNegativeNumbers && isNegativeNumber(a) && !flagExists(sc, p, flagName) {
+			argType = argIsPositional
+		}
+
+		switch argType {
+		case argIsFinal:
+			return args, nil, nil
+		case argIsFlagWithSpace:
+			// skip the value of flags that take one.  The built-in flags take
+			// no value, and unknown flags only take one when passed through.
+			if p.ShowHelpWithHFlag && (flagName == helpFlagShortName || flagName == helpFlagLongName) {
+				continue
+			}
+			if p.ShowVersionWithVersionFlag && flagName == versionFlagLongName {
+				continue
+			}
+			if p.ShowHelpAllWithHelpAllFlag && flagName == helpAllFlagLongName {
+				continue
+			}
+			if !flagExists(sc, p, flagName) {
+				nextArgIsValue := i+1 < len(args) && determineArgType(args[i+1]) == argIsPositional
+				skipNext = sc.PassThroughUnknownFlags && nextArgIsValue
+				continue
+			}
+			if _, ok := flagNoOptionDefault(sc, p, flagName); ok || flagIsBool(sc, p, flagName) {
+				continue
+			}
+			skipNext = true
+		case argIsPositional:
+			positionalCount++
+			var foundSubcommand bool
+			for _, cmd := range sc.Subcommands {
+				if positionalCount == cmd.Position && (a == cmd.Name || a == cmd.ShortName) {
+					sc = cmd
+					positionalCount = 0
+					foundSubcommand = true
+					break
+				}
+			}
+			if foundSubcommand || !sc.DisableInterspersed || sc.variadicPositional() != nil {
+				continue
+			}
+			var highestPosition int
+			for _, pv := range sc.PositionalFlags {
+				if pv.Position > highestPosition {
+					highestPosition = pv.Position
+				}
+			}
+			if positionalCount > highestPosition {
+				return args[:i], args[i:], sc
+			}
+		}
+	}
+	return args, nil, nil
+}
+
 // findArgsNotInParsedValues finds arguments not used in parsed values.  The
 // incoming args should be in the order supplied by the user and should not
 // include the invoked binary, which is normally the first thing in os.Args.
@@ -91,6 +230,19 @@ func findArgsNotInParsedValues(args []string, parsedValues []parsedValue) []stri
 	// 	DebugMode = false
 	// }()
 
+	// index the parsed values by key and by positional value so that each arg
+	// finds the first parsed value it matches without scanning them all
+	keyIndex := make(map[string]int)
+	positionalIndex := make(map[string]int)
+	for i, pv := range parsedValues {
+		if _, exists := keyIndex[pv.Key]; !exists {
+			keyIndex[pv.Key] = i
+		}
+		if _, exists := positionalIndex[pv.Value]; pv.IsPositional && !exists {
+			positionalIndex[pv.Value] = i
+		}
+	}
+
 	var argsNotUsed []string
 	var skipNext bool
 	for _, a := range args {
@@ -112,36 +264,29 @@ func findArgsNotInParsedValues(args []string, parsedValues []parsedValue) []stri
 		arg := parseFlagToName(a)
 
 		// skip args that start with 'test.' because they are injected with go test
-		debugPrint("flagsNotParsed: checking arg for test prefix:", arg)
 		if strings.HasPrefix(arg, "test.") {
-			debugPrint("skipping test. prefixed arg has test prefix:", arg)
 			continue
 		}
-		debugPrint("flagsNotParsed: flag is not a test. flag:", arg)
+
+		// find the first parsed value where this arg was a key or a positional
+		first := -1
+		for _, i := range []int{indexOf(keyIndex, arg), indexOf(positionalIndex, arg), indexOf(positionalIndex, a)} {
+			if i >= 0 && (first < 0 || i < first) {
+				first = i
+			}
+		}
 
 		// indicates that we found this arg used in one of the parsed values. Used
 		// to indicate which values should be added to argsNotUsed.
-		var foundArgUsed bool
-
-		// search all args for a corresponding parsed value
-		for _, pv := range parsedValues {
-			// this argumenet was a key
-			// debugPrint(pv.Key, "==", arg)
-			debugPrint(pv.Key + "==" + arg + " || (" + strconv.FormatBool(pv.IsPositional) + " && " + pv.Value + " == " + arg + ")")
-			if pv.Key == arg || (pv.IsPositional && pv.Value == arg) {
-				debugPrint("Found matching parsed arg for " + pv.Key)
-				foundArgUsed = true // the arg was used in this parsedValues set
-				// if the value is not a positional value and the parsed value had a
-				// value that was not blank, we skip the next value in the argument list
-				if !pv.IsPositional && len(pv.Value) > 0 {
-					skipNext = true
-					break
-				}
-			}
-			// this prevents excessive parsed values from being checked after we find
-			// the arg used for the first time
-			if foundArgUsed {
-				break
+		foundArgUsed := first >= 0
+
+		// if the value is not a positional value and the parsed value had a
+		// value that was not blank, we skip the next value in the argument list
+		// unless the value was joined to the flag with an equals sign
+		if foundArgUsed {
+			pv := parsedValues[first]
+			if !pv.IsPositional && len(pv.Value) > 0 && determineArgType(a) != argIsFlagWithValue {
+				skipNext = true
 			}
 		}
 
@@ -155,17 +300,55 @@ func findArgsNotInParsedValues(args []string, parsedValues []parsedValue) []stri
 	return argsNotUsed
 }
 
-// ShowVersionAndExit shows the version of this parser
-func (p *Parser) ShowVersionAndExit() {
-	fmt.Println("Version:", p.Version)
-	exitOrPanic(0)
+// subcommandChain returns the root subcommand followed by each subcommand
+// used, ending with the last subcommand used.  Only the root is returned
+// before parsing.
+func (p *Parser) subcommandChain() []*Subcommand {
+	if p.subcommandContext.parent == nil {
+		return []*Subcommand{&p.Subcommand}
+	}
+	var chain []*Subcommand
+	for sc := p.subcommandContext; sc != nil; sc = sc.parent {
+		chain = append([]*Subcommand{sc}, chain...)
+	}
+	return chain
+}
+
+// Visit calls fn for each flag that was set during the last parse, in the
+// order they were added, starting with the root parser and ending with the
+// last subcommand used
+func (p *Parser) Visit(fn func(*Flag)) {
+	p.VisitAll(func(f *Flag) {
+		if f.IsSet() {
+			fn(f)
+		}
+	})
+}
+
+// VisitAll calls fn for every flag in the chain of subcommands used, in the
+// order they were added, starting with the root parser and ending with the
+// last subcommand used
+func (p *Parser) VisitAll(fn func(*Flag)) {
+	for _, sc := range p.subcommandChain() {
+		for _, f := range sc.Flags {
+			fn(f)
+		}
+	}
+}
+
+// indexOf returns the index stored for the key, or -1 if there is none
+func indexOf(index map[string]int, key string) int {
+	if i, exists := index[key]; exists {
+		return i
+	}
+	return -1
 }
 
 // SetHelpTemplate sets the go template this parser will use when rendering
 // Help.
 func (p *Parser) SetHelpTemplate(tmpl string) error {
 	var err error
-	p.HelpTemplate = template.New(helpFlagLongName)
+	p.HelpTemplate = template.New(helpFlagLongName).Funcs(helpTemplateFuncs).Funcs(styleTemplateFuncs).Funcs(messageTemplateFuncs)
 	p.HelpTemplate, err = p.HelpTemplate.Parse(tmpl)
 	if err != nil {
 		return err
@@ -178,16 +361,54 @@ func (p *Parser) Parse() error {
 	return p.ParseArgs(os.Args[1:])
 }
 
+// exit exits with the specified code using ExitFunc if it is set
+func (p *Parser) exit(code int) {
+	if p.ExitFunc != nil {
+		p.ExitFunc(code)
+	}
+	if p.PanicInsteadOfExit {
+		panic("Panic instead of exit with code: " + strconv.Itoa(code))
+	}
+	os.Exit(code)
+}
+
+// copyDeprecatedGlobals copies the deprecated DebugMode and PanicInsteadOfExit
+// package variables into this parser when they changed since they were last
+// copied
+func (p *Parser) copyDeprecatedGlobals() {
+	if DebugMode != p.globalDebugMode {
+		p.DebugMode = DebugMode
+		p.globalDebugMode = DebugMode
+	}
+	if PanicInsteadOfExit != p.globalPanicInsteadOfExit {
+		p.PanicInsteadOfExit = PanicInsteadOfExit
+		p.globalPanicInsteadOfExit = PanicInsteadOfExit
+	}
+}
+
+// debug sends a debug event to the parser's DebugLogger.  When there is no
+// DebugLogger and debug mode is enabled, events are written to os.Stderr so
+// they are kept apart from program and help output.
+func (p *Parser) debug(msg string, args ...interface{}) {
+	if p.DebugLogger != nil {
+		p.DebugLogger.Debug(msg, args...)
+		return
+	}
+	if p.DebugMode {
+		NewDebugLogger(os.Stderr).Debug(msg, args...)
+	}
+}
+
 // ShowHelp shows Help without an error message
 func (p *Parser) ShowHelp() {
-	debugPrint("showing help for", p.subcommandContext.Name)
+	p.debug("showing help", "subcommand", p.subcommandContext.Name)
 	p.ShowHelpWithMessage("")
 }
 
 // ShowHelpAndExit shows parser help and exits with status code 2
 func (p *Parser) ShowHelpAndExit(message string) {
 	p.ShowHelpWithMessage(message)
-	exitOrPanic(2)
+	p.exit(2)
 }
 
 // ShowHelpWithMessage shows the Help for this parser with an optional string error
@@ -197,7 +418,7 @@ func (p *Parser) ShowHelpWithMessage(message string) {
 	// create a new Help values template and extract values into it
 	help := Help{}
 	help.ExtractValues(p, message)
-	err := p.HelpTemplate.Execute(p.Output, help)
+	err := p.helpTemplate().Execute(p.Output, help)
 	if err != nil {
 		fmt.Fprintln(p.Output, "Error rendering Help template:", err)
 	}
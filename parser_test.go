@@ -60,3 +60,98 @@ func TestFindArgsNotInParsedValues(t *testing.T) {
 		t.Fatal("Invalid number of unused args found.  Expected 1 but found", len(unusedArgs))
 	}
 }
+
+func TestVisitAndLookup(t *testing.T) {
+	p := NewParser("ourtool")
+	sc := NewSubcommand("serve")
+	p.AttachSubcommand(sc, 1)
+	var verbose bool
+	port := 8080
+	var host string
+	var tags []string
+	p.Bool(&verbose, "v", "verbose", "verbose output")
+	p.StringSlice(&tags, "t", "tag", "tags")
+	sc.Int(&port, "p", "port", "port to listen on")
+	sc.String(&host, "H", "host", "host to listen on")
+
+	if err := p.ParseArgs([]string{"serve", "--port", "8080", "-t", "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var set []string
+	p.Visit(func(f *Flag) {
+		set = append(set, f.LongName)
+	})
+	if len(set) != 2 || set[0] != "tag" || set[1] != "port" {
+		t.Fatal("visited set flags incorrect:", set)
+	}
+
+	var all []string
+	p.VisitAll(func(f *Flag) {
+		all = append(all, f.LongName)
+	})
+	if len(all) != 4 {
+		t.Fatal("visited all flags incorrect:", all)
+	}
+
+	f := sc.Lookup("p")
+	if f == nil || !f.IsSet() || f.RawValue() != "8080" || f.DefaultValue() != "8080" {
+		t.Fatal("port flag lookup incorrect:", f)
+	}
+	if f := sc.Lookup("verbose"); f == nil || f.IsSet() || f.DefaultValue() != "false" {
+		t.Fatal("expected inherited unset verbose flag, got", f)
+	}
+	if f := sc.Lookup("host"); f == nil || f.IsSet() || f.RawValue() != "" {
+		t.Fatal("expected unset host flag, got", f)
+	}
+	if p.Lookup("port") != nil {
+		t.Fatal("expected subcommand flag to not be found on the parser")
+	}
+}
+
+func TestDeprecatedGlobals(t *testing.T) {
+	defer func(enabled bool) {
+		PanicInsteadOfExit = enabled
+		ResetParser()
+	}(PanicInsteadOfExit)
+
+	PanicInsteadOfExit = true
+	if p := NewParser("TestDeprecatedGlobals"); !p.PanicInsteadOfExit {
+		t.Fatal("expected new parsers to copy PanicInsteadOfExit")
+	}
+
+	ResetParser()
+	PanicInsteadOfExit = false
+	ParseArgs([]string{})
+	if DefaultParser.PanicInsteadOfExit {
+		t.Fatal("expected disabling PanicInsteadOfExit to apply to the default parser")
+	}
+
+	ResetParser()
+	SetPanicInsteadOfExit(true)
+	ParseArgs([]string{})
+	if !DefaultParser.PanicInsteadOfExit {
+		t.Fatal("expected the default parser to keep its own setting")
+	}
+}
+
+// TestSplitNonInterspersedArgsBuiltInFlags tests that the built-in flags do
+// not take the first positional of a subcommand with interspersed flags
+// disabled as their value
+func TestSplitNonInterspersedArgsBuiltInFlags(t *testing.T) {
+	p := NewParser("ourtool")
+	p.ShowHelpAllWithHelpAllFlag = true
+	exec := NewSubcommand("exec")
+	exec.DisableInterspersed = true
+	p.AttachSubcommand(exec, 1)
+
+	for _, flag := range []string{"--version", "--help-all", "--help", "-h"} {
+		args, passthroughArgs, sc := p.splitNonInterspersedArgs([]string{"exec", flag, "kubectl", "get"})
+		if len(args) != 2 || sc != exec {
+			t.Fatal("expected the args to be split after", flag, "got:", args, passthroughArgs)
+		}
+		if len(passthroughArgs) != 2 || passthroughArgs[0] != "kubectl" {
+			t.Fatal("unexpected passthrough args after", flag, "got:", passthroughArgs)
+		}
+	}
+}
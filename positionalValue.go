@@ -1,14 +1,59 @@
 package flaggy
 
+import "reflect"
+
 // PositionalValue represents a value which is determined by its position
 // relative to where a subcommand was detected.
 type PositionalValue struct {
 	Name          string // used in documentation only
 	Description   string
-	AssignmentVar *string // the var that will get this variable
-	Position      int     // the position, not including switches, of this variable
-	Required      bool    // this subcommand must always be specified
-	Found         bool    // was this positional found during parsing?
-	Hidden        bool    // indicates this positional value should be hidden from help
-	defaultValue  string  // used for help output
+	AssignmentVar interface{}   // the var that will get this variable, any type supported by flags
+	Position      int           // the position, not including switches, of this variable
+	Required      bool          // this subcommand must always be specified
+	Found         bool          // was this positional found during parsing?
+	Hidden        bool          // indicates this positional value should be hidden from help
+	Variadic      bool          // consumes all remaining positional args into a slice
+	MinCount      int           // the minimum number of args a variadic positional requires
+	MaxCount      int           // the maximum number of args a variadic positional accepts, 0 is unlimited
+	Validators    []Validator   // run in order after each value is assigned
+	defaultValue  string        // used for help output
+	initialValue  reflect.Value // the value of the assignment var before the first parse
+}
+
+// assignValue parses the supplied value and assigns it to the positional's
+// assignment var as if it was passed to a flag of the same type
+func (pv *PositionalValue) assignValue(value string) error {
+	// strings are appended directly so that variadic values containing commas
+	// are not split like they are for string slice flags
+	if v, ok := pv.AssignmentVar.(*[]string); ok {
+		*v = append(*v, value)
+	} else if err := pv.flag().identifyAndAssignValue(value); err != nil {
+		return err
+	}
+	return runValidators(pv.Validators, pv.AssignmentVar, MessageInvalidPositionalValue, pv.Name, value)
+}
+
+// valueAsString returns the current value of the positional's assignment var
+// as a string
+func (pv *PositionalValue) valueAsString() (string, error) {
+	return pv.flag().returnAssignmentVarValueAsString()
+}
+
+// flag returns a flag sharing this positional's assignment var so that
+// values are parsed the same way flag values are
+func (pv *PositionalValue) flag() *Flag {
+	return &Flag{
+		LongName:      pv.Name,
+		AssignmentVar: pv.AssignmentVar,
+		parsed:        true,
+	}
+}
+
+// helpName returns the name of this positional as displayed in help output.
+// ex) FILE...
+func (pv *PositionalValue) helpName() string {
+	if pv.Variadic {
+		return pv.Name + "..."
+	}
+	return pv.Name
 }
@@ -0,0 +1,140 @@
+package flaggy
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// responseFilePrefix marks an argument as a response file to be expanded
+const responseFilePrefix = "@"
+
+// expandResponseFiles replaces every @path argument with the arguments read
+// from that file.  Response files may reference other response files.  An
+// argument starting with @@ is kept as a literal argument starting with a
+// single @.  Arguments after -- are not expanded.
+func expandResponseFiles(args []string) ([]string, error) {
+	expanded, _, err := expandResponseFileArgs(args, nil)
+	return expanded, err
+}
+
+// expandResponseFileArgs expands response file arguments.  The stack holds
+// the absolute paths of the response files being expanded to detect cycles.
+// terminated indicates that a -- was found, either among the args or in a
+// response file, after which nothing more is expanded.
+func expandResponseFileArgs(args []string, stack []string) (expanded []string, terminated bool, err error) {
+	for i, a := range args {
+		if a == "--" {
+			return append(expanded, args[i:]...), true, nil
+		}
+
+		if strings.HasPrefix(a, responseFilePrefix+responseFilePrefix) {
+			expanded = append(expanded, strings.TrimPrefix(a, responseFilePrefix))
+			continue
+		}
+
+		if !strings.HasPrefix(a, responseFilePrefix) || len(a) == 1 {
+			expanded = append(expanded, a)
+			continue
+		}
+
+		path, err := filepath.Abs(strings.TrimPrefix(a, responseFilePrefix))
+		if err != nil {
+			return nil, false, err
+		}
+		for _, included := range stack {
+			if included == path {
+				return nil, false, newMessageError(MessageResponseFileLoop, "path", path, "chain", strings.Join(append(stack, path), " -> "))
+			}
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, false, &MessageError{Key: MessageResponseFileRead, Err: err}
+		}
+		fileArgs, err := splitResponseFile(string(contents))
+		if err != nil {
+			return nil, false, &MessageError{Key: MessageResponseFileParse, Params: []string{"path", path}, Err: err}
+		}
+		fileArgs, fileTerminated, err := expandResponseFileArgs(fileArgs, append(stack, path))
+		if err != nil {
+			return nil, false, err
+		}
+		expanded = append(expanded, fileArgs...)
+
+		// the args after a -- in a response file are literal, and so are the
+		// rest of the args
+		if fileTerminated {
+			return append(expanded, args[i+1:]...), true, nil
+		}
+	}
+	return expanded, false, nil
+}
+
+// splitResponseFile splits the contents of a response file into arguments
+// using shell-like rules.  Arguments are separated by whitespace, single
+// quotes preserve everything literally, double quotes allow backslash
+// escapes, and a # at the start of an argument comments out the rest of the
+// line.
+func splitResponseFile(contents string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var inArg bool
+	var quote rune
+	var escaped bool
+
+	for _, r := range contents {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				current.WriteRune(r)
+			}
+		case quote == '#':
+			if r == '\n' {
+				quote = 0
+			}
+		case r == '#' && !inArg:
+			quote = '#'
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, newMessageError(MessageResponseFileBackslash)
+	}
+	if quote == '\'' || quote == '"' {
+		return nil, newMessageError(MessageResponseFileQuote, "quote", string(quote))
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,138 @@
+package flaggy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitResponseFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "whitespace", contents: "-a  one\n\t--b=two\r\n", want: []string{"-a", "one", "--b=two"}},
+		{name: "single_quotes", contents: `--name 'a "b" \c'`, want: []string{"--name", `a "b" \c`}},
+		{name: "double_quotes", contents: `--name "a 'b' \"c\" \\"`, want: []string{"--name", `a 'b' "c" \`}},
+		{name: "joined_quotes", contents: `--name=a' 'b`, want: []string{"--name=a b"}},
+		{name: "empty_quotes", contents: `'' ""`, want: []string{"", ""}},
+		{name: "escaped_space", contents: `a\ b`, want: []string{"a b"}},
+		{name: "comments", contents: "# a comment\n-a # trailing\nb#c", want: []string{"-a", "b#c"}},
+		{name: "unterminated", contents: `"abc`, wantErr: true},
+		{name: "trailing_backslash", contents: `abc\`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitResponseFile(tt.contents)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitResponseFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitResponseFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandResponseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaggy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	nested := filepath.Join(dir, "nested.txt")
+	outer := filepath.Join(dir, "outer.txt")
+	writeFile(t, nested, "--name 'nested value'")
+	writeFile(t, outer, "-v\n@"+nested+"\n@@literal")
+
+	p := NewParser("TestExpandResponseFiles")
+	p.ExpandResponseFiles = true
+	var verbose bool
+	var name string
+	var positionals []string
+	p.Bool(&verbose, "v", "verbose", "verbose")
+	p.String(&name, "n", "name", "name")
+	p.AddVariadicPositionalValue(&positionals, "ARGS", 1, 0, 0, "args")
+
+	err = p.ParseArgs([]string{"@" + outer, "@@other", "--", "@" + outer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verbose || name != "nested value" {
+		t.Fatal("response file flags incorrect:", verbose, name)
+	}
+	if !reflect.DeepEqual(positionals, []string{"@literal", "@other"}) {
+		t.Fatal("escaped response file args incorrect:", positionals)
+	}
+	if !reflect.DeepEqual(p.TrailingArguments, []string{"@" + outer}) {
+		t.Fatal("response files after -- should not be expanded:", p.TrailingArguments)
+	}
+}
+
+func TestExpandResponseFilesTerminator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaggy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	writeFile(t, first, "-v -- @"+second)
+	writeFile(t, second, "--name value")
+
+	p := NewParser("TestExpandResponseFilesTerminator")
+	p.ExpandResponseFiles = true
+	var verbose bool
+	var name string
+	p.Bool(&verbose, "v", "verbose", "verbose")
+	p.String(&name, "n", "name", "name")
+
+	err = p.ParseArgs([]string{"@" + first, "@" + second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verbose || name != "" {
+		t.Fatal("response files after -- should not be expanded:", verbose, name)
+	}
+	if !reflect.DeepEqual(p.TrailingArguments, []string{"@" + second, "@" + second}) {
+		t.Fatal("args after -- in a response file should be trailing arguments:", p.TrailingArguments)
+	}
+}
+
+func TestExpandResponseFilesCycle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaggy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	writeFile(t, a, "@"+b)
+	writeFile(t, b, "@"+a)
+
+	_, err = expandResponseFiles([]string{"@" + a})
+	if err == nil || !strings.Contains(err.Error(), "includes itself") {
+		t.Fatalf("got: %v; want: a response file cycle error", err)
+	}
+
+	_, err = expandResponseFiles([]string{"@" + filepath.Join(dir, "missing.txt")})
+	if err == nil {
+		t.Fatal("expected an error for a missing response file")
+	}
+}
+
+// writeFile writes the contents to the file at path or fails the test
+func writeFile(t *testing.T, path string, contents string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
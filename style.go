@@ -0,0 +1,116 @@
+package flaggy
+
+import (
+	"os"
+	"text/template"
+)
+
+// ColorMode determines if help output is styled with ANSI escape sequences
+type ColorMode int
+
+const (
+	// ColorAuto styles help output when Output is a terminal, unless the
+	// NO_COLOR environment variable is set.  Setting CLICOLOR_FORCE to a value
+	// other than 0 styles help output even when Output is not a terminal.
+	ColorAuto ColorMode = iota
+	// ColorAlways always styles help output
+	ColorAlways
+	// ColorNever never styles help output
+	ColorNever
+)
+
+// Theme holds the styles applied to each part of help output.  Each style
+// is a list of ANSI SGR parameters separated by semicolons, such as "1" for
+// bold, "2" for dim or "1;31" for bold red.  Parts with an empty style are
+// not styled.
+type Theme struct {
+	Heading     string // section headings. ex) Flags:
+	Command     string // the command name and subcommand names
+	Flag        string // flag names
+	Placeholder string // flag value placeholders
+	Required    string // required markers
+	Default     string // default values
+	Message     string // the message shown with help, usually an error
+}
+
+// DefaultTheme is the theme used by parsers without a Theme
+var DefaultTheme = Theme{
+	Heading:     "1",
+	Command:     "1;36",
+	Flag:        "32",
+	Placeholder: "2",
+	Required:    "31",
+	Default:     "2",
+	Message:     "1;31",
+}
+
+// styleTemplateFuncs are the styling functions available to help templates.
+// They return their text unchanged until help output is styled.
+var styleTemplateFuncs = Theme{}.templateFuncs()
+
+// templateFuncs returns the help template functions that apply this theme
+func (t Theme) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"heading":     styler(t.Heading),
+		"command":     styler(t.Command),
+		"flag":        styler(t.Flag),
+		"placeholder": styler(t.Placeholder),
+		"required":    styler(t.Required),
+		"default":     styler(t.Default),
+		"message":     styler(t.Message),
+	}
+}
+
+// styler returns a function that wraps text in the escape sequences of the
+// specified style
+func styler(style string) func(text string) string {
+	return func(text string) string {
+		if style == "" || text == "" {
+			return text
+		}
+		return "\x1b[" + style + "m" + text + "\x1b[0m"
+	}
+}
+
+// colorEnabled indicates that help output should be styled
+func (p *Parser) colorEnabled() bool {
+	switch p.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	if f, ok := p.Output.(*os.File); ok {
+		return terminalWidth(f) > 0
+	}
+	return false
+}
+
+// helpTemplate returns the help template with the messages of the parser's
+// catalog, and the styling functions of the parser's theme when help output
+// is styled
+func (p *Parser) helpTemplate() *template.Template {
+	colored := p.colorEnabled()
+	if !colored && p.Messages == nil {
+		return p.HelpTemplate
+	}
+	tmpl, err := p.HelpTemplate.Clone()
+	if err != nil {
+		return p.HelpTemplate
+	}
+	tmpl.Funcs(p.messageTemplateFuncs())
+	if !colored {
+		return tmpl
+	}
+	theme := DefaultTheme
+	if p.Theme != nil {
+		theme = *p.Theme
+	}
+	return tmpl.Funcs(theme.templateFuncs())
+}
@@ -0,0 +1,87 @@
+package flaggy
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	p := NewParser("color")
+	p.Output = &bytes.Buffer{}
+	if p.colorEnabled() {
+		t.Fatal("expected no color when output is not a terminal")
+	}
+
+	os.Setenv("CLICOLOR_FORCE", "1")
+	defer os.Unsetenv("CLICOLOR_FORCE")
+	if !p.colorEnabled() {
+		t.Fatal("expected CLICOLOR_FORCE to force color")
+	}
+
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+	if p.colorEnabled() {
+		t.Fatal("expected NO_COLOR to disable color")
+	}
+
+	p.Color = ColorAlways
+	if !p.colorEnabled() {
+		t.Fatal("expected ColorAlways to force color")
+	}
+
+	os.Unsetenv("NO_COLOR")
+	p.Color = ColorNever
+	if p.colorEnabled() {
+		t.Fatal("expected ColorNever to disable color")
+	}
+}
+
+func TestStyledHelp(t *testing.T) {
+	p := NewParser("styled")
+	p.ShowVersionWithVersionFlag = false
+	p.HelpWidth = -1
+	var port int
+	p.Int(&port, "p", "port", "The port to listen on.")
+	p.AddPositionalValue(new(string), "target", 1, true, "The target.")
+	if err := p.ParseArgs([]string{"here"}); err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	p.Output = buf
+
+	p.Color = ColorAlways
+	p.Theme = &Theme{Heading: "1", Flag: "32", Placeholder: "2", Required: "31", Message: "35"}
+	p.ShowHelpWithMessage("bad input")
+	styled := buf.String()
+	for _, want := range []string{
+		"\x1b[1mFlags:\x1b[0m",
+		"\x1b[32m-p --port\x1b[0m \x1b[2mint\x1b[0m",
+		"\x1b[31m(Required)\x1b[0m",
+		"\x1b[35mbad input\x1b[0m",
+	} {
+		if !strings.Contains(styled, want) {
+			t.Errorf("expected styled help to contain %q, got:\n%q", want, styled)
+		}
+	}
+
+	buf.Reset()
+	p.Color = ColorNever
+	p.ShowHelpWithMessage("bad input")
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("expected unstyled help, got:\n%q", buf.String())
+	}
+
+	// styling does not change how help lines up
+	plainLines := strings.Split(buf.String(), "\n")
+	for i, line := range strings.Split(styled, "\n") {
+		if displayWidth(line) != displayWidth(plainLines[i]) {
+			t.Errorf("line %d is %d columns styled and %d unstyled", i, displayWidth(line), displayWidth(plainLines[i]))
+		}
+	}
+
+	if got := displayWidth("\x1b[1;31mabc\x1b[0m"); got != 3 {
+		t.Fatalf("expected escape sequences to take no columns, got %d", got)
+	}
+}
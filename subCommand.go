@@ -1,10 +1,12 @@
 package flaggy
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +30,25 @@ type Subcommand struct {
 	AdditionalHelpAppend  string        // additional appended message when Help is displayed
 	Used                  bool          // indicates this subcommand was found and parsed
 	Hidden                bool          // indicates this subcommand should be hidden from help
+	Category              string        // groups this subcommand under its own section in its parent's help output
+	Examples              []Example     // example command lines shown in help
+	DisableInterspersed   bool          // stops parsing flags at the first arg after this subcommand's positionals
+	TrailingArguments     []string      // trailing arguments when this is the last subcommand used
+	// PassThroughUnknownFlags collects unknown flags and their values into
+	// UnknownFlags, in their original order, instead of treating them as
+	// unexpected.  A following arg that is not a flag is taken as the value.
+	PassThroughUnknownFlags bool
+	UnknownFlags            []string
+	unknownFlags            []unknownFlag // unknown flags found while parsing this subcommand
+	parent                  *Subcommand   // the subcommand or parser this subcommand is attached to
+	flagIndex               flagIndex     // flags of the command tree by name, only set while parsing
+}
+
+// unknownFlag is a flag that no subcommand in use recognized
+type unknownFlag struct {
+	key   string   // the flag name as recorded in parsed values
+	value string   // the value taken from the following arg, if any
+	args  []string // the original args of the flag and its value
 }
 
 // NewSubcommand creates a new subcommand that can have flags or PositionalFlags
@@ -46,9 +67,27 @@ func NewSubcommand(name string) *Subcommand {
 // parseAllFlagsFromArgs parses the non-positional flags such as -f or -v=value
 // out of the supplied args and returns the resulting positional items in order,
 // all the flag names found (without values), a bool to indicate if help was
-// requested, and any errors found during parsing
-func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string, bool, error) {
+// requested, and any errors found during parsing.  Flag values are only
+// assigned when assign is set, which is done once the last subcommand used is
+// known so that flags are assigned within its scope.
+func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string, assign bool) ([]string, bool, error) {
 	var positionalOnlyArguments []string
+
+	// the parsers that accept flag values for this subcommand
+	var parsers []ArgumentParser
+	if assign {
+		parsers = sc.scopedParsers()
+	}
+
+	// assignValue sets the value in this subcommand or the ancestor it is
+	// inherited from
+	assignValue := func(key string, value string) (bool, error) {
+		valueSet, err := setValueForParsers(key, value, parsers...)
+		if valueSet && err == nil {
+			p.debug("assigned flag value", "subcommand", sc.Name, "flag", key, "value", value)
+		}
+		return valueSet, err
+	}
 	var helpRequested bool // indicates the user has supplied -h and we
 	// should render help if we are the last subcommand
 
@@ -63,8 +102,6 @@ func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string,
 	// find all the normal flags (not positional) and parse them out
 	for i, a := range args {
 
-		debugPrint("parsing arg:", a)
-
 		// evaluate if there is a following arg to avoid panics
 		var nextArgExists bool
 		var nextArg string
@@ -84,25 +121,32 @@ func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string,
 		// skip this run if specified
 		if skipNext {
 			skipNext = false
-			debugPrint("skipping flag because it is an arg:", a)
+			if !assign {
+				p.debug("classified arg", "subcommand", sc.Name, "arg", a, "type", "flagValue")
+			}
 			continue
 		}
 
-		// parse the flag into its name for consideration without dashes
+		// parse the flag into its name for consideration without dashes.  Args
+		// without dashes are also the built-in help and version flags, unless
+		// the built-in help or version subcommand handles them.
 		flagName := parseFlagToName(a)
+		isFlag := strings.HasPrefix(a, "-")
+		isHelpFlag := isFlag || !p.ShowHelpWithHelpCommand
+		isVersionFlag := isFlag || !p.ShowVersionWithVersionCommand
 
 		// if the flag being passed is version or v and the option to display
 		// version with version flags, then display version
 		if p.ShowVersionWithVersionFlag {
-			if flagName == versionFlagLongName {
-				p.ShowVersionAndExit()
+			if isVersionFlag && flagName == versionFlagLongName {
+				p.showRequestedVersionAndExit(args)
 			}
 		}
 
 		// if the show Help on h flag option is set, then show Help when h or Help
 		// is passed as an option
 		if p.ShowHelpWithHFlag {
-			if flagName == helpFlagShortName || flagName == helpFlagLongName {
+			if isHelpFlag && (flagName == helpFlagShortName || flagName == helpFlagLongName) {
 				// Ensure this is the last subcommand passed so we give the correct
 				// help output
 				helpRequested = true
@@ -110,11 +154,28 @@ func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string,
 			}
 		}
 
+		// if the show help all with help all flag option is set, then show help
+		// with every nested subcommand when help-all is passed as an option
+		if p.ShowHelpAllWithHelpAllFlag {
+			if isFlag && flagName == helpAllFlagLongName {
+				p.helpAllRequested = true
+				helpRequested = true
+				continue
+			}
+		}
+
 		// determine what kind of flag this is
 		argType := determineArgType(a)
 
-		// strip flags from arg
-		debugPrint("Parsing flag named", a, "of type", argType)
+		// negative numbers are values rather than flags, unless a flag with
+		// that name exists
+		if argType == argIsFlagWithSpace && p.AllowNegativeNumbers && isNegativeNumber(a) && !flagExists(sc, p, flagName) {
+			argType = argIsPositional
+		}
+
+		if !assign {
+			p.debug("classified arg", "subcommand", sc.Name, "arg", a, "type", argType)
+		}
 
 		// depending on the flag type, parse the key and value out, then apply it
 		switch argType {
@@ -127,7 +188,9 @@ func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string,
 			// we can determine if its a subcommand or positional value later
 			positionalOnlyArguments = append(positionalOnlyArguments, a)
 			// track this as a parsed value with the subcommand
-			sc.addParsedPositionalValue(a)
+			if !assign {
+				sc.addParsedPositionalValue(a)
+			}
 		case argIsFlagWithSpace: // a flag with a space. ex) -k v or --key value
 			a = parseFlagToName(a)
 
@@ -136,9 +199,7 @@ func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string,
 			// if the flag is a bool flag, then we check for a following positional
 			// and skip it if necessary
 			if flagIsBool(sc, p, a) {
-				debugPrint(sc.Name, "bool flag", a, "next var is:", nextArg)
-				// set the value in this subcommand and its root parser
-				valueSet, err := setValueForParsers(a, "true", p, sc)
+				valueSet, err := assignValue(a, "true")
 				// if an error occurs, just return it and quit parsing
 				if err != nil {
 					return []string{}, false, err
@@ -155,19 +216,64 @@ func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string,
 				continue
 			}
 
+			// flags with a no-option default take their value only when joined
+			// with an equals sign, so the next arg is left alone
+			if noOptionDefault, ok := flagNoOptionDefault(sc, p, a); ok {
+				valueSet, err := assignValue(a, noOptionDefault)
+				if err != nil {
+					return []string{}, false, err
+				}
+
+				// the value is left blank so that the following arg is not
+				// considered used by this flag
+				if valueSet {
+					sc.addParsedFlag(a, "")
+				}
+				continue
+			}
+
+			// unknown flags are collected along with their value, if one follows,
+			// when this subcommand passes them through
+			if sc.PassThroughUnknownFlags && sc.scopedFlag(a) == nil {
+				flagArgs := []string{args[i]}
+				var value string
+				if nextArgExists && determineArgType(nextArg) == argIsPositional {
+					value = nextArg
+					flagArgs = append(flagArgs, nextArg)
+					skipNext = true
+				}
+				if assign {
+					p.debug("passed through unknown flag", "subcommand", sc.Name, "args", flagArgs)
+					sc.unknownFlags = append(sc.unknownFlags, unknownFlag{key: a, value: value, args: flagArgs})
+				}
+				continue
+			}
+
+			// unknown flags are left to be reported as unexpected rather than
+			// missing a value when they are last, as they are when the args
+			// after them are passed through
+			if !nextArgExists && !flagExists(sc, p, a) {
+				continue
+			}
+
 			skipNext = true
 			// debugPrint(sc.Name, "NOT bool flag", a)
 
 			// if the next arg was not found, then show a Help message
 			if !nextArgExists {
-				p.ShowHelpWithMessage("Expected a following arg for flag " + a + ", but it did not exist.")
-				exitOrPanic(2)
+				p.ShowHelpWithMessage(p.Message(MessageMissingFlagValue, "flag", a))
+				p.exit(2)
 			}
-			valueSet, err := setValueForParsers(a, nextArg, p, sc)
+			valueSet, err := assignValue(a, nextArg)
 			if err != nil {
 				return []string{}, false, err
 			}
 
+			// unknown flags do not consume the -- that starts trailing arguments
+			if !valueSet && determineArgType(nextArg) == argIsFinal && !flagExists(sc, p, a) {
+				skipNext = false
+			}
+
 			// log all parsed values in the subcommand
 			if valueSet {
 				sc.addParsedFlag(a, nextArg)
@@ -179,8 +285,8 @@ func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string,
 			// parse flag into key and value and apply to subcommand flags
 			key, val := parseArgWithValue(a)
 
-			// set the value in this subcommand and its root parser
-			valueSet, err := setValueForParsers(key, val, p, sc)
+			// set the value in this subcommand or the ancestor it is inherited from
+			valueSet, err := assignValue(key, val)
 			if err != nil {
 				return []string{}, false, err
 			}
@@ -188,6 +294,9 @@ func (sc *Subcommand) parseAllFlagsFromArgs(p *Parser, args []string) ([]string,
 			// log all values parsed by the subcommand
 			if valueSet {
 				sc.addParsedFlag(a, val)
+			} else if assign && sc.PassThroughUnknownFlags && sc.scopedFlag(key) == nil {
+				p.debug("passed through unknown flag", "subcommand", sc.Name, "args", []string{args[i]})
+				sc.unknownFlags = append(sc.unknownFlags, unknownFlag{key: a, args: []string{args[i]}})
 			}
 		}
 	}
@@ -214,11 +323,10 @@ func (sc *Subcommand) findAllParsedValues() []parsedValue {
 // and subcommands parsed is returned so that the parser can ultimately decide
 // if there were any unexpected values supplied by the user
 func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
-	debugPrint("- Parsing subcommand", sc.Name, "with depth of", depth, "and args", args)
+	p.debug("parsing subcommand", "subcommand", sc.Name, "depth", depth, "args", args)
 
 	// if a command is parsed, its used
 	sc.Used = true
-	debugPrint("used subcommand", sc.Name, sc.ShortName)
 	if len(sc.Name) > 0 {
 		sc.addParsedPositionalValue(sc.Name)
 	}
@@ -226,6 +334,9 @@ func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
 		sc.addParsedPositionalValue(sc.ShortName)
 	}
 
+	// unknown flags are only kept if this ends up being the last subcommand
+	sc.unknownFlags = nil
+
 	// as subcommands are used, they become the context of the parser.  This helps
 	// us understand how to display help based on which subcommand is being used
 	p.subcommandContext = sc
@@ -233,17 +344,19 @@ func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
 	// ensure that help and version flags are not used if the parser has the
 	// built-in help and version flags enabled
 	if p.ShowHelpWithHFlag {
-		sc.ensureNoConflictWithBuiltinHelp()
+		sc.ensureNoConflictWithBuiltinHelp(p)
 	}
 	if p.ShowVersionWithVersionFlag {
-		sc.ensureNoConflictWithBuiltinVersion()
+		sc.ensureNoConflictWithBuiltinVersion(p)
+	}
+	if p.ShowHelpAllWithHelpAllFlag {
+		sc.ensureNoConflictWithBuiltinHelpAll(p)
 	}
 
 	// Parse the normal flags out of the argument list and return the positionals
 	// (subcommands and positional values), along with the flags used.
-	// Then the flag values are applied to the parent parser and the current
-	// subcommand being parsed.
-	positionalOnlyArguments, helpRequested, err := sc.parseAllFlagsFromArgs(p, args)
+	// The flag values are applied once the last subcommand used is found.
+	positionalOnlyArguments, helpRequested, err := sc.parseAllFlagsFromArgs(p, args, false)
 	if err != nil {
 		return err
 	}
@@ -252,6 +365,34 @@ func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
 	// appended a second time
 	p.trailingArgumentsExtracted = true
 
+	// the built-in help subcommand shows help for the subcommands or help
+	// topic named after it instead of running them
+	if sc == &p.Subcommand && p.hasHelpCommand() && len(positionalOnlyArguments) > 0 && positionalOnlyArguments[0] == helpCommandName {
+		p.showHelpCommand(positionalOnlyArguments[1:])
+	}
+
+	// the built-in version subcommand shows the version instead of running
+	if sc == &p.Subcommand && p.hasVersionCommand() && len(positionalOnlyArguments) > 0 && positionalOnlyArguments[0] == versionCommandName {
+		p.showRequestedVersionAndExit(args)
+	}
+
+	// a variadic positional consumes the positional arguments from its position
+	// onward, leaving enough for the positional values declared after it
+	variadic := sc.variadicPositional()
+	var variadicLength int
+	var variadicCount int
+	if variadic != nil {
+		variadicLength = len(positionalOnlyArguments) - depth - (variadic.Position - 1)
+		for _, val := range sc.PositionalFlags {
+			if val.Position > variadic.Position {
+				variadicLength--
+			}
+		}
+		if variadicLength < 0 {
+			variadicLength = 0
+		}
+	}
+
 	// loop over positional values and look for their matching positional
 	// parameter, or their positional command.  If neither are found, then
 	// we throw an error
@@ -269,30 +410,57 @@ func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
 		}
 		parsedArgCount++
 
+		// values beyond a variadic positional are shifted back to the position
+		// they would have without the variadic values
+		var isVariadicValue bool
+		if variadic != nil && relativeDepth >= variadic.Position {
+			if relativeDepth < variadic.Position+variadicLength {
+				isVariadicValue = true
+			} else {
+				relativeDepth = relativeDepth - variadicLength + 1
+			}
+		}
+
 		// determine subcommands and parse them by positional value and name
 		for _, cmd := range sc.Subcommands {
 			// debugPrint("Subcommand being compared", relativeDepth, "==", cmd.Position, "and", v, "==", cmd.Name, "==", cmd.ShortName)
-			if relativeDepth == cmd.Position && (v == cmd.Name || v == cmd.ShortName) {
-				debugPrint("Decending into positional subcommand", cmd.Name, "at relativeDepth", relativeDepth, "and absolute depth", depth+1)
+			if !isVariadicValue && relativeDepth == cmd.Position && (v == cmd.Name || v == cmd.ShortName) {
+				p.debug("descending into subcommand", "subcommand", sc.Name, "child", cmd.Name, "position", relativeDepth)
 				return cmd.parse(p, args, depth+parsedArgCount) // continue recursive positional parsing
 			}
 		}
 
 		// determine positional args and parse them by positional value and name
 		var foundPositional bool
-		for _, val := range sc.PositionalFlags {
-			if relativeDepth == val.Position {
-				debugPrint("Found a positional value at relativePos:", relativeDepth, "value:", v)
+		if isVariadicValue {
+			if variadic.MaxCount == 0 || variadicCount < variadic.MaxCount {
+				p.debug("assigned positional value", "subcommand", sc.Name, "positional", variadic.Name, "position", relativeDepth, "value", v)
+				if variadicCount == 0 {
+					variadic.defaultValue, _ = variadic.valueAsString()
+				}
+				if err := variadic.assignValue(v); err != nil {
+					return err
+				}
+				variadicCount++
+				foundPositional = true
+				variadic.Found = true
+			}
+		} else {
+			for _, val := range sc.PositionalFlags {
+				if relativeDepth == val.Position {
+					p.debug("assigned positional value", "subcommand", sc.Name, "positional", val.Name, "position", relativeDepth, "value", v)
 
-				// set original value for help output
-				val.defaultValue = *val.AssignmentVar
+					// set original value for help output
+					val.defaultValue, _ = val.valueAsString()
 
-				// defrerence the struct pointer, then set the pointer property within it
-				*val.AssignmentVar = v
-				// debugPrint("set positional to value", *val.AssignmentVar)
-				foundPositional = true
-				val.Found = true
-				break
+					// parse the value into the assignment var
+					if err := val.assignValue(v); err != nil {
+						return err
+					}
+					foundPositional = true
+					val.Found = true
+					break
+				}
 			}
 		}
 
@@ -300,7 +468,7 @@ func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
 		// were not used, display a useful message with subcommand options.
 		if !foundPositional {
 			if p.ShowHelpOnUnexpected {
-				debugPrint("No positional at position", relativeDepth)
+				p.debug("unexpected argument", "subcommand", sc.Name, "arg", v, "position", relativeDepth)
 				var foundSubcommandAtDepth bool
 				for _, cmd := range sc.Subcommands {
 					if cmd.Position == relativeDepth {
@@ -312,7 +480,7 @@ func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
 				// as a suggestion to the user before exiting.
 				if foundSubcommandAtDepth {
 					// determine which name to use in upcoming help output
-					fmt.Fprintln(p.Output, sc.Name+":", "No subcommand or positional value found at position", strconv.Itoa(relativeDepth)+".")
+					fmt.Fprintln(p.Output, p.Message(MessageNoSubcommandAtPosition, "subcommand", sc.Name, "position", strconv.Itoa(relativeDepth)))
 					var output string
 					for _, cmd := range sc.Subcommands {
 						if cmd.Hidden {
@@ -323,15 +491,15 @@ func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
 					// if there are available subcommands, let the user know
 					if len(output) > 0 {
 						output = strings.TrimLeft(output, " ")
-						fmt.Println("Available subcommands:", output)
+						fmt.Fprintln(p.Output, p.Message(MessageAvailableSubcommands, "subcommands", output))
 					}
-					exitOrPanic(2)
+					p.exit(2)
 				}
 
 				// if there were not any flags or subcommands at this position at all, then
 				// throw an error (display Help if necessary)
-				p.ShowHelpWithMessage("Unexpected argument: " + v)
-				exitOrPanic(2)
+				p.ShowHelpWithMessage(p.Message(MessageUnexpectedArgument, "arg", v))
+				p.exit(2)
 			} else {
 				// if no positional value was registered at this position, but the parser is not
 				// configured to show help when any unexpected command is found, add this positional
@@ -343,26 +511,46 @@ func (sc *Subcommand) parse(p *Parser, args []string, depth int) error {
 		}
 	}
 
+	// this is the last subcommand used, so flag values are applied to it and
+	// the persistent flags it inherits from its ancestors
+	if _, _, err := sc.parseAllFlagsFromArgs(p, args, true); err != nil {
+		return err
+	}
+
+	// as the last subcommand used, it also receives the trailing arguments and
+	// the unknown flags it passes through
+	if sc != &p.Subcommand {
+		sc.TrailingArguments = append(sc.TrailingArguments, p.TrailingArguments...)
+	}
+	for _, f := range sc.unknownFlags {
+		sc.UnknownFlags = append(sc.UnknownFlags, f.args...)
+		sc.addParsedFlag(f.key, f.value)
+	}
+
 	// if help was requested and we should show help when h is passed,
-	if helpRequested && p.ShowHelpWithHFlag {
+	if helpRequested && (p.ShowHelpWithHFlag || p.helpAllRequested) {
 		p.ShowHelp()
-		exitOrPanic(0)
+		p.exit(0)
 	}
 
 	// find any positionals that were not used on subcommands that were
 	// found and throw help (unknown argument) in the global parse or subcommand
 	for _, pv := range p.PositionalFlags {
 		if pv.Required && !pv.Found {
-			p.ShowHelpWithMessage("Required global positional variable " + pv.Name + " not found at position " + strconv.Itoa(pv.Position))
-			exitOrPanic(2)
+			p.ShowHelpWithMessage(p.Message(MessageMissingGlobalPositional, "name", pv.Name, "position", strconv.Itoa(pv.Position)))
+			p.exit(2)
 		}
 	}
 	for _, pv := range sc.PositionalFlags {
 		if pv.Required && !pv.Found {
-			p.ShowHelpWithMessage("Required positional of subcommand " + sc.Name + " named " + pv.Name + " not found at position " + strconv.Itoa(pv.Position))
-			exitOrPanic(2)
+			p.ShowHelpWithMessage(p.Message(MessageMissingPositional, "subcommand", sc.Name, "name", pv.Name, "position", strconv.Itoa(pv.Position)))
+			p.exit(2)
 		}
 	}
+	if variadic != nil && variadicCount < variadic.MinCount {
+		p.ShowHelpWithMessage(p.Message(MessageTooFewVariadicValues, "subcommand", sc.Name, "name", variadic.Name, "min", strconv.Itoa(variadic.MinCount), "count", strconv.Itoa(variadicCount)))
+		p.exit(2)
+	}
 
 	return nil
 }
@@ -411,13 +599,17 @@ func (sc *Subcommand) AttachSubcommand(newSC *Subcommand, relativePosition int)
 		}
 	}
 
-	// ensure no positionals at this depth
+	// ensure no positionals at this depth or variadic positionals before it
 	for _, other := range sc.PositionalFlags {
 		if newSC.Position == other.Position {
 			log.Panicln("Unable to add subcommand because a positional value already exists at position " + strconv.Itoa(newSC.Position) + ": " + other.Name)
 		}
+		if other.Variadic && newSC.Position > other.Position {
+			log.Panicln("Unable to add subcommand because a variadic positional value already exists before position " + strconv.Itoa(newSC.Position) + ": " + other.Name)
+		}
 	}
 
+	newSC.parent = sc
 	sc.Subcommands = append(sc.Subcommands, newSC)
 }
 
@@ -449,12 +641,53 @@ func (sc *Subcommand) String(assignmentVar *string, shortName string, longName s
 	sc.add(assignmentVar, shortName, longName, description)
 }
 
+// Value adds a new flag of any type that implements flag.Value.  Values
+// that implement IsBoolFlag() returning true do not require a value.
+func (sc *Subcommand) Value(assignmentVar flag.Value, shortName string, longName string, description string) {
+	sc.add(assignmentVar, shortName, longName, description)
+}
+
+// OptionalString adds a new string flag that can be passed with or without
+// a value.  When passed bare, noOptionDefault is assigned.  A value must be
+// joined with an equals sign.  ex) --color or --color=never
+func (sc *Subcommand) OptionalString(assignmentVar *string, shortName string, longName string, noOptionDefault string, description string) {
+	sc.add(assignmentVar, shortName, longName, description)
+	f := sc.Flags[len(sc.Flags)-1]
+	f.NoOptionDefault = noOptionDefault
+	f.OptionalValue = true
+}
+
 // StringSlice adds a new slice of strings flag
 // Specify the flag multiple times to fill the slice
 func (sc *Subcommand) StringSlice(assignmentVar *[]string, shortName string, longName string, description string) {
 	sc.add(assignmentVar, shortName, longName, description)
 }
 
+// StringMap adds a new map of strings flag.  Values are key=value pairs
+// split on the first equals sign.  Specify the flag multiple times or
+// separate pairs with commas to fill the map.  ex) --label env=prod,team=core
+func (sc *Subcommand) StringMap(assignmentVar *map[string]string, shortName string, longName string, description string) {
+	sc.add(assignmentVar, shortName, longName, description)
+}
+
+// IntMap adds a new map of ints flag.  Values are key=value pairs split on
+// the first equals sign.  Specify the flag multiple times or separate pairs
+// with commas to fill the map.
+func (sc *Subcommand) IntMap(assignmentVar *map[string]int, shortName string, longName string, description string) {
+	sc.add(assignmentVar, shortName, longName, description)
+}
+
+// Map adds a new map flag with string keys and values of any supported flag
+// type, such as *map[string]time.Duration or *map[string][]string.  Values
+// are key=value pairs split on the first equals sign.  Map panics if the
+// assignment var is not a pointer to a map with string keys.
+func (sc *Subcommand) Map(assignmentVar interface{}, shortName string, longName string, description string) {
+	if !isMapFlag(assignmentVar) {
+		log.Panicln("Unable to add map flag " + longName + " " + shortName + " because its assignment var is not a pointer to a map with string keys")
+	}
+	sc.add(assignmentVar, shortName, longName, description)
+}
+
 // Bool adds a new bool flag
 func (sc *Subcommand) Bool(assignmentVar *bool, shortName string, longName string, description string) {
 	sc.add(assignmentVar, shortName, longName, description)
@@ -655,6 +888,63 @@ func (sc *Subcommand) IPMaskSlice(assignmentVar *[]net.IPMask, shortName string,
 // AddPositionalValue adds a positional value to the subcommand.  the
 // relativePosition starts at 1 and is relative to the subcommand it belongs to
 func (sc *Subcommand) AddPositionalValue(assignmentVar *string, name string, relativePosition int, required bool, description string) {
+	sc.AddTypedPositionalValue(assignmentVar, name, relativePosition, required, description)
+}
+
+// AddTypedPositionalValue adds a positional value of any type supported by
+// flags to the subcommand, such as *int, *time.Duration or *net.IP.  the
+// relativePosition starts at 1 and is relative to the subcommand it belongs to
+func (sc *Subcommand) AddTypedPositionalValue(assignmentVar interface{}, name string, relativePosition int, required bool, description string) {
+	sc.addPositionalValue(&PositionalValue{
+		Name:          name,
+		Position:      relativePosition,
+		AssignmentVar: assignmentVar,
+		Required:      required,
+		Description:   description,
+	})
+}
+
+// AddVariadicPositionalValue adds a positional value that consumes all
+// remaining positional args into a slice of any type supported by flags,
+// such as *[]string or *[]int.  Positional values at later positions are
+// filled from the last args.  ex) cp SRC... DST.  A maxCount of 0 allows
+// any number of values.
+func (sc *Subcommand) AddVariadicPositionalValue(assignmentVar interface{}, name string, relativePosition int, minCount int, maxCount int, description string) {
+	if reflect.TypeOf(assignmentVar).Kind() != reflect.Ptr || reflect.TypeOf(assignmentVar).Elem().Kind() != reflect.Slice {
+		log.Panicln("Unable to add variadic positional value " + name + " because its assignment var is not a pointer to a slice")
+	}
+
+	for _, other := range sc.PositionalFlags {
+		if other.Variadic {
+			log.Panicln("Unable to add variadic positional value " + name + " because " + other.Name + " is already variadic")
+		}
+	}
+
+	// ensure no subcommands after this position
+	for _, other := range sc.Subcommands {
+		if other.Position > relativePosition {
+			log.Panicln("Unable to add variadic positional value " + name + " because a subcommand, " + other.Name + ", already exists after position: " + strconv.Itoa(relativePosition))
+		}
+	}
+
+	sc.addPositionalValue(&PositionalValue{
+		Name:          name,
+		Position:      relativePosition,
+		AssignmentVar: assignmentVar,
+		Required:      minCount > 0,
+		Description:   description,
+		Variadic:      true,
+		MinCount:      minCount,
+		MaxCount:      maxCount,
+	})
+}
+
+// addPositionalValue ensures the positional value does not conflict with
+// others on the subcommand and adds it
+func (sc *Subcommand) addPositionalValue(newPositionalValue *PositionalValue) {
+	name := newPositionalValue.Name
+	relativePosition := newPositionalValue.Position
+
 	// ensure no other positionals are at this depth
 	for _, other := range sc.PositionalFlags {
 		if relativePosition == other.Position {
@@ -669,15 +959,23 @@ func (sc *Subcommand) AddPositionalValue(assignmentVar *string, name string, rel
 		}
 	}
 
-	newPositionalValue := PositionalValue{
-		Name:          name,
-		Position:      relativePosition,
-		AssignmentVar: assignmentVar,
-		Required:      required,
-		Description:   description,
-		defaultValue:  *assignmentVar,
+	var err error
+	newPositionalValue.defaultValue, err = newPositionalValue.valueAsString()
+	if err != nil {
+		log.Panicln("Unable to add positional value " + name + ": " + err.Error())
+	}
+	sc.PositionalFlags = append(sc.PositionalFlags, newPositionalValue)
+}
+
+// variadicPositional returns the variadic positional value of this
+// subcommand, or nil if it has none
+func (sc *Subcommand) variadicPositional() *PositionalValue {
+	for _, val := range sc.PositionalFlags {
+		if val.Variadic {
+			return val
+		}
 	}
-	sc.PositionalFlags = append(sc.PositionalFlags, &newPositionalValue)
+	return nil
 }
 
 // SetValueForKey sets the value for the specified key. If setting a bool
@@ -686,37 +984,121 @@ func (sc *Subcommand) AddPositionalValue(assignmentVar *string, name string, rel
 func (sc *Subcommand) SetValueForKey(key string, value string) (bool, error) {
 	// debugPrint("Looking to set key", key, "to value", value)
 	// check for and assign flags that match the key
-	for _, f := range sc.Flags {
-		// debugPrint("Evaluating string flag", f.ShortName, "==", key, "||", f.LongName, "==", key)
-		if f.ShortName == key || f.LongName == key {
-			// debugPrint("Setting string value for", key, "to", value)
-			if err := f.identifyAndAssignValue(value); err != nil {
-				return false, err
-			}
-			return true, nil
+	if f := sc.ownFlag(key); f != nil {
+		// debugPrint("Setting string value for", key, "to", value)
+		if err := f.setValue(value); err != nil {
+			return false, err
 		}
+		return true, nil
 	}
 
 	// debugPrint(sc.Name, "was unable to find a key named", key, "to set to value", value)
 	return false, nil
 }
 
+// scopedParsers returns the parsers that accept flag values when this is the
+// last subcommand used.  Its own flags are checked first, followed by the
+// persistent flags of each ancestor from nearest to the root parser.
+func (sc *Subcommand) scopedParsers() []ArgumentParser {
+	parsers := []ArgumentParser{sc}
+	for parent := sc.parent; parent != nil; parent = parent.parent {
+		parsers = append(parsers, inheritedFlags{parent})
+	}
+	return parsers
+}
+
+// Lookup returns the flag with the specified short or long name that this
+// subcommand accepts, including persistent flags inherited from its
+// ancestors.  Nil is returned if there is no such flag.
+func (sc *Subcommand) Lookup(name string) *Flag {
+	return sc.scopedFlag(name)
+}
+
+// scopedFlag returns the flag with the specified name that is accepted when
+// this is the last subcommand used, or nil if there is none
+func (sc *Subcommand) scopedFlag(name string) *Flag {
+	if f := sc.ownFlag(name); f != nil {
+		return f
+	}
+	for parent := sc.parent; parent != nil; parent = parent.parent {
+		if f := parent.persistentFlag(name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// inheritedFlags sets the values of the persistent flags of a subcommand
+// when one of its descendants is the last subcommand used
+type inheritedFlags struct {
+	sc *Subcommand
+}
+
+// SetValueForKey sets the value for the specified key if it belongs to a
+// persistent flag.  The returned bool indicates that a value was set.
+func (i inheritedFlags) SetValueForKey(key string, value string) (bool, error) {
+	f := i.sc.persistentFlag(key)
+	if f == nil {
+		return false, nil
+	}
+	if err := f.setValue(value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetFlagScope sets the scope of the flag with the specified short or long
+// name, which determines if descendant subcommands accept it
+func (sc *Subcommand) SetFlagScope(name string, scope FlagScope) {
+	for _, f := range sc.Flags {
+		if f.HasName(name) {
+			f.Scope = scope
+			return
+		}
+	}
+	log.Panicln("Unable to set scope because no flag named " + name + " exists on subcommand " + sc.Name)
+}
+
+// SetFlagCategory sets the category of the flag with the specified short or
+// long name, which groups it under its own section in help output
+func (sc *Subcommand) SetFlagCategory(name string, category string) {
+	for _, f := range sc.Flags {
+		if f.HasName(name) {
+			f.Category = category
+			return
+		}
+	}
+	log.Panicln("Unable to set category because no flag named " + name + " exists on subcommand " + sc.Name)
+}
+
+// SetMapKeyPolicy sets how keys supplied more than once are handled for the
+// map flag with the specified short or long name
+func (sc *Subcommand) SetMapKeyPolicy(name string, policy MapKeyPolicy) {
+	for _, f := range sc.Flags {
+		if f.HasName(name) && isMapFlag(f.AssignmentVar) {
+			f.MapKeyPolicy = policy
+			return
+		}
+	}
+	log.Panicln("Unable to set map key policy because no map flag named " + name + " exists on subcommand " + sc.Name)
+}
+
 // ensureNoConflictWithBuiltinHelp ensures that the flags on this subcommand do
 // not conflict with the builtin help flags (-h or --help). Exits the program
 // if a conflict is found.
-func (sc *Subcommand) ensureNoConflictWithBuiltinHelp() {
+func (sc *Subcommand) ensureNoConflictWithBuiltinHelp(p *Parser) {
 	for _, f := range sc.Flags {
 		if f.LongName == helpFlagLongName {
-			sc.exitBecauseOfHelpFlagConflict(f.LongName)
+			sc.exitBecauseOfHelpFlagConflict(p, f.LongName)
 		}
 		if f.LongName == helpFlagShortName {
-			sc.exitBecauseOfHelpFlagConflict(f.LongName)
+			sc.exitBecauseOfHelpFlagConflict(p, f.LongName)
 		}
 		if f.ShortName == helpFlagLongName {
-			sc.exitBecauseOfHelpFlagConflict(f.ShortName)
+			sc.exitBecauseOfHelpFlagConflict(p, f.ShortName)
 		}
 		if f.ShortName == helpFlagShortName {
-			sc.exitBecauseOfHelpFlagConflict(f.ShortName)
+			sc.exitBecauseOfHelpFlagConflict(p, f.ShortName)
 		}
 	}
 }
@@ -724,35 +1106,60 @@ func (sc *Subcommand) ensureNoConflictWithBuiltinHelp() {
 // ensureNoConflictWithBuiltinVersion ensures that the flags on this subcommand do
 // not conflict with the builtin version flag (--version). Exits the program
 // if a conflict is found.
-func (sc *Subcommand) ensureNoConflictWithBuiltinVersion() {
+func (sc *Subcommand) ensureNoConflictWithBuiltinVersion(p *Parser) {
 	for _, f := range sc.Flags {
 		if f.LongName == versionFlagLongName {
-			sc.exitBecauseOfVersionFlagConflict(f.LongName)
+			sc.exitBecauseOfVersionFlagConflict(p, f.LongName)
 		}
 		if f.ShortName == versionFlagLongName {
-			sc.exitBecauseOfVersionFlagConflict(f.ShortName)
+			sc.exitBecauseOfVersionFlagConflict(p, f.ShortName)
+		}
+	}
+}
+
+// ensureNoConflictWithBuiltinHelpAll ensures that the flags on this
+// subcommand do not conflict with the builtin help-all flag.  Exits the
+// program if a conflict is found.
+func (sc *Subcommand) ensureNoConflictWithBuiltinHelpAll(p *Parser) {
+	for _, f := range sc.Flags {
+		if f.LongName == helpAllFlagLongName {
+			sc.exitBecauseOfHelpAllFlagConflict(p, f.LongName)
+		}
+		if f.ShortName == helpAllFlagLongName {
+			sc.exitBecauseOfHelpAllFlagConflict(p, f.ShortName)
 		}
 	}
 }
 
+// exitBecauseOfHelpAllFlagConflict exits the program with a message about how
+// to prevent flags being defined from conflicting with the builtin flags.
+func (sc *Subcommand) exitBecauseOfHelpAllFlagConflict(p *Parser, flagName string) {
+	fmt.Fprintln(p.Output, `Flag with name '`+flagName+`' conflicts with the internal --help-all flag in flaggy.
+
+You must either change the flag's name, or disable flaggy's internal help-all
+flag with 'flaggy.DefaultParser.ShowHelpAllWithHelpAllFlag = false'.  If you are
+using a custom parser, you must instead set '.ShowHelpAllWithHelpAllFlag = false' on it.`)
+	p.exit(1)
+}
+
 // exitBecauseOfVersionFlagConflict exits the program with a message about how to prevent
 // flags being defined from conflicting with the builtin flags.
-func (sc *Subcommand) exitBecauseOfVersionFlagConflict(flagName string) {
-	fmt.Println(`Flag with name '` + flagName + `' conflicts with the internal --version flag in flaggy.
+func (sc *Subcommand) exitBecauseOfVersionFlagConflict(p *Parser, flagName string) {
+	fmt.Fprintln(p.Output, `Flag with name '`+flagName+`' conflicts with the internal --version flag in flaggy.
 
 You must either change the flag's name, or disable flaggy's internal version
 flag with 'flaggy.DefaultParser.ShowVersionWithVersionFlag = false'.  If you are using
 a custom parser, you must instead set '.ShowVersionWithVersionFlag = false' on it.`)
-	exitOrPanic(1)
+	p.exit(1)
 }
 
 // exitBecauseOfHelpFlagConflict exits the program with a message about how to prevent
 // flags being defined from conflicting with the builtin flags.
-func (sc *Subcommand) exitBecauseOfHelpFlagConflict(flagName string) {
-	fmt.Println(`Flag with name '` + flagName + `' conflicts with the internal --help or -h flag in flaggy.
+func (sc *Subcommand) exitBecauseOfHelpFlagConflict(p *Parser, flagName string) {
+	fmt.Fprintln(p.Output, `Flag with name '`+flagName+`' conflicts with the internal --help or -h flag in flaggy.
 
 You must either change the flag's name, or disable flaggy's internal help
 flag with 'flaggy.DefaultParser.ShowHelpWithHFlag = false'.  If you are using
 a custom parser, you must instead set '.ShowHelpWithHFlag = false' on it.`)
-	exitOrPanic(1)
+	p.exit(1)
 }
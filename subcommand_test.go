@@ -1,8 +1,10 @@
 package flaggy_test
 
 import (
+	"io/ioutil"
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -829,3 +831,357 @@ func TestParseErrorsAreReportedRegression(t *testing.T) {
 	os.Args = []string{"prog", "--int", "abc"}
 	flaggy.Parse()
 }
+
+// TestOptionalValueFlag tests flags that can be passed with or without a
+// value and that a bare flag never consumes the following positional.
+func TestOptionalValueFlag(t *testing.T) {
+	p := flaggy.NewParser("TestOptionalValueFlag")
+	var color string
+	var positional string
+	p.OptionalString(&color, "c", "color", "always", "colorize output")
+	p.AddPositionalValue(&positional, "file", 1, true, "a test positional")
+
+	err := p.ParseArgs([]string{"--color", "file.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if color != "always" {
+		t.Fatal("expected bare optional flag to be set to its default, got", color)
+	}
+	if positional != "file.txt" {
+		t.Fatal("expected positional to not be consumed by the optional flag, got", positional)
+	}
+
+	p = flaggy.NewParser("TestOptionalValueFlag")
+	color = ""
+	p.OptionalString(&color, "c", "color", "always", "colorize output")
+	err = p.ParseArgs([]string{"--color=never"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if color != "never" {
+		t.Fatal("expected explicit optional flag value to be set, got", color)
+	}
+
+	p = flaggy.NewParser("TestOptionalValueFlag")
+	color = "auto"
+	positional = ""
+	p.OptionalString(&color, "", "color", "", "colorize output")
+	p.AddPositionalValue(&positional, "file", 1, true, "a test positional")
+	err = p.ParseArgs([]string{"--color", "file.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if color != "" || positional != "file.txt" {
+		t.Fatal("expected an empty default to be assigned without consuming the positional, got", color, positional)
+	}
+}
+
+// TestTypedPositional tests positional values bound to non-string types
+func TestTypedPositional(t *testing.T) {
+	p := flaggy.NewParser("TestTypedPositional")
+	var count int
+	var timeout time.Duration
+	var ip net.IP
+	p.AddTypedPositionalValue(&count, "count", 1, true, "an int positional")
+	p.AddTypedPositionalValue(&timeout, "timeout", 2, true, "a duration positional")
+	p.AddTypedPositionalValue(&ip, "ip", 3, true, "an ip positional")
+
+	err := p.ParseArgs([]string{"5", "1m", "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 || timeout != time.Minute || !ip.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Fatal("typed positionals incorrect:", count, timeout, ip)
+	}
+
+	p = flaggy.NewParser("TestTypedPositional")
+	p.AddTypedPositionalValue(&count, "count", 1, true, "an int positional")
+	err = p.ParseArgs([]string{"five"})
+	if err == nil {
+		t.Fatal("expected an error parsing an invalid int positional")
+	}
+}
+
+// TestVariadicPositional tests variadic positionals followed by a fixed
+// positional, like cp SRC... DST
+func TestVariadicPositional(t *testing.T) {
+	p := flaggy.NewParser("cp")
+	var sources []string
+	var destination string
+	var force bool
+	p.Bool(&force, "f", "force", "overwrite")
+	p.AddVariadicPositionalValue(&sources, "SRC", 1, 1, 0, "source files")
+	p.AddPositionalValue(&destination, "DST", 2, true, "destination")
+
+	err := p.ParseArgs([]string{"a,1.txt", "-f", "b.txt", "c.txt", "dir"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 3 || sources[0] != "a,1.txt" || sources[2] != "c.txt" {
+		t.Fatal("variadic positional incorrect:", sources)
+	}
+	if destination != "dir" || !force {
+		t.Fatal("positional after variadic incorrect:", destination, force)
+	}
+
+	p = flaggy.NewParser("kill")
+	var pids []int
+	p.AddVariadicPositionalValue(&pids, "PID", 1, 1, 2, "process ids")
+	err = p.ParseArgs([]string{"10", "20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pids) != 2 || pids[1] != 20 {
+		t.Fatal("typed variadic positional incorrect:", pids)
+	}
+}
+
+// TestVariadicPositionalMinCount tests that a variadic positional with too few
+// values exits
+func TestVariadicPositionalMinCount(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Expected crash on too few variadic values")
+		}
+	}()
+	p := flaggy.NewParser("cp")
+	var sources []string
+	var destination string
+	p.AddVariadicPositionalValue(&sources, "SRC", 1, 1, 0, "source files")
+	p.AddPositionalValue(&destination, "DST", 2, true, "destination")
+	p.ParseArgs([]string{"dir"})
+}
+
+// TestVariadicPositionalMaxCount tests that a variadic positional with too
+// many values exits
+func TestVariadicPositionalMaxCount(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Expected crash on too many variadic values")
+		}
+	}()
+	p := flaggy.NewParser("kill")
+	var pids []int
+	p.AddVariadicPositionalValue(&pids, "PID", 1, 1, 2, "process ids")
+	p.ParseArgs([]string{"10", "20", "30"})
+}
+
+// TestNegativeNumbers tests negative numbers as flag values and positionals
+func TestNegativeNumbers(t *testing.T) {
+	p := flaggy.NewParser("calc")
+	add := flaggy.NewSubcommand("add")
+	p.AttachSubcommand(add, 1)
+	var offset int
+	var shift time.Duration
+	var a, b float64
+	var five bool
+	p.Int(&offset, "o", "offset", "the offset")
+	p.Duration(&shift, "s", "shift", "the shift")
+	p.Bool(&five, "5", "five", "a digit flag")
+	add.AddTypedPositionalValue(&a, "A", 1, true, "first number")
+	add.AddTypedPositionalValue(&b, "B", 2, true, "second number")
+
+	err := p.ParseArgs([]string{"add", "--offset", "-5", "-3", "-s", "-1h", "-0.5", "-5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != -5 || shift != -time.Hour {
+		t.Fatal("negative flag values incorrect:", offset, shift)
+	}
+	if a != -3 || b != -0.5 {
+		t.Fatal("negative positionals incorrect:", a, b)
+	}
+	if !five {
+		t.Fatal("expected a defined digit flag to be parsed as a flag")
+	}
+}
+
+// TestNegativeNumbersDisabled tests that negative numbers are flags when
+// disabled on the parser
+func TestNegativeNumbersDisabled(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Expected crash on negative number parsed as an unknown flag")
+		}
+	}()
+	p := flaggy.NewParser("calc")
+	p.AllowNegativeNumbers = false
+	var a string
+	p.AddPositionalValue(&a, "A", 1, false, "first number")
+	p.ParseArgs([]string{"-3"})
+}
+
+// TestDisableInterspersed tests that args after the positionals of a
+// subcommand with interspersed flags disabled are passed through untouched
+func TestDisableInterspersed(t *testing.T) {
+	p := flaggy.NewParser("ourtool")
+	exec := flaggy.NewSubcommand("exec")
+	exec.DisableInterspersed = true
+	p.AttachSubcommand(exec, 1)
+	var verbose, dryRun bool
+	var output, target string
+	p.Bool(&verbose, "v", "verbose", "verbose output")
+	p.String(&output, "o", "output", "output format")
+	exec.Bool(&dryRun, "d", "dry-run", "only print the command")
+	exec.AddPositionalValue(&target, "TARGET", 1, true, "the target")
+
+	err := p.ParseArgs([]string{"-v", "exec", "prod", "--dry-run", "kubectl", "get", "pods", "-o", "yaml", "--", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verbose || !dryRun || target != "prod" {
+		t.Fatal("flags before the passthrough args incorrect:", verbose, dryRun, target)
+	}
+	if output != "" {
+		t.Fatal("expected passthrough flag to not be parsed, got", output)
+	}
+	want := []string{"kubectl", "get", "pods", "-o", "yaml", "--", "x"}
+	if strings.Join(exec.TrailingArguments, " ") != strings.Join(want, " ") {
+		t.Fatal("subcommand trailing arguments incorrect:", exec.TrailingArguments)
+	}
+	if strings.Join(p.TrailingArguments, " ") != strings.Join(want, " ") {
+		t.Fatal("parser trailing arguments incorrect:", p.TrailingArguments)
+	}
+}
+
+// TestDisableInterspersedUnknownFlags tests that unknown flags before the
+// first positional only take it as their value when they are passed through
+func TestDisableInterspersedUnknownFlags(t *testing.T) {
+	newParser := func() (*flaggy.Parser, *flaggy.Subcommand) {
+		p := flaggy.NewParser("ourtool")
+		p.ShowHelpOnUnexpected = false
+		exec := flaggy.NewSubcommand("exec")
+		exec.DisableInterspersed = true
+		p.AttachSubcommand(exec, 1)
+		return p, exec
+	}
+
+	p, exec := newParser()
+	if err := p.ParseArgs([]string{"exec", "--unknown", "kubectl", "get"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(exec.TrailingArguments, " "); got != "kubectl get" {
+		t.Fatal("expected the unknown flag to take no value, got trailing arguments:", exec.TrailingArguments)
+	}
+
+	p, exec = newParser()
+	exec.PassThroughUnknownFlags = true
+	if err := p.ParseArgs([]string{"exec", "--unknown", "kubectl", "get"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(exec.UnknownFlags, " "); got != "--unknown kubectl" {
+		t.Fatal("expected the passed through flag to take a value, got:", exec.UnknownFlags)
+	}
+	if got := strings.Join(exec.TrailingArguments, " "); got != "get" {
+		t.Fatal("unexpected trailing arguments:", exec.TrailingArguments)
+	}
+}
+
+// TestPassThroughUnknownFlags tests that unknown flags are collected in order
+// with their values on a subcommand that passes them through
+func TestPassThroughUnknownFlags(t *testing.T) {
+	p := flaggy.NewParser("wrapper")
+	run := flaggy.NewSubcommand("run")
+	run.PassThroughUnknownFlags = true
+	p.AttachSubcommand(run, 1)
+	var verbose bool
+	var image string
+	p.Bool(&verbose, "v", "verbose", "verbose output")
+	run.AddPositionalValue(&image, "IMAGE", 1, true, "the image")
+
+	err := p.ParseArgs([]string{"run", "--rm", "-e", "A=1", "-v", "--name=web", "alpine", "--publish", "--", "sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verbose || image != "alpine" {
+		t.Fatal("known flags and positionals incorrect:", verbose, image)
+	}
+	want := "--rm -e A=1 --name=web --publish"
+	if got := strings.Join(run.UnknownFlags, " "); got != want {
+		t.Fatalf("unknown flags incorrect. got: %s; want: %s", got, want)
+	}
+	if len(run.TrailingArguments) != 1 || run.TrailingArguments[0] != "sh" {
+		t.Fatal("subcommand trailing arguments incorrect:", run.TrailingArguments, p.TrailingArguments)
+	}
+}
+
+// TestFlagScopes tests that persistent flags are inherited by descendant
+// subcommands while local flags are only accepted by their own subcommand
+func TestFlagScopes(t *testing.T) {
+	p := flaggy.NewParser("ourtool")
+	remote := flaggy.NewSubcommand("remote")
+	add := flaggy.NewSubcommand("add")
+	p.AttachSubcommand(remote, 1)
+	remote.AttachSubcommand(add, 1)
+	var verbose, quiet, force bool
+	var tags []string
+	var config string
+	p.Bool(&verbose, "v", "verbose", "verbose output")
+	p.StringSlice(&tags, "t", "tag", "tags")
+	p.String(&config, "c", "config", "config file")
+	p.SetFlagScope("config", flaggy.LocalScope)
+	remote.Bool(&quiet, "q", "quiet", "quiet output")
+	remote.SetFlagScope("q", flaggy.PersistentScope)
+	add.Bool(&force, "f", "force", "force")
+
+	err := p.ParseArgs([]string{"-t", "a", "remote", "add", "-v", "-q", "-f"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verbose || !quiet || !force {
+		t.Fatal("inherited flags not set:", verbose, quiet, force)
+	}
+	if len(tags) != 1 || tags[0] != "a" {
+		t.Fatal("expected root slice flag to be assigned once, got", tags)
+	}
+}
+
+// TestLocalFlagRejected tests that a local flag of a parent subcommand is
+// unexpected when a descendant subcommand is used
+func TestLocalFlagRejected(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected local flag of parent to be rejected")
+		}
+	}()
+	flaggy.PanicInsteadOfExit = true
+	p := flaggy.NewParser("ourtool")
+	remote := flaggy.NewSubcommand("remote")
+	add := flaggy.NewSubcommand("add")
+	p.AttachSubcommand(remote, 1)
+	remote.AttachSubcommand(add, 1)
+	var quiet bool
+	remote.Bool(&quiet, "q", "quiet", "quiet output")
+	remote.SetFlagScope("q", flaggy.LocalScope)
+	p.Output = ioutil.Discard
+	p.ParseArgs([]string{"remote", "add", "-q"})
+}
+
+// TestIntermediateFlagInherited tests that a flag with the default scope on
+// a subcommand is accepted before and after a nested subcommand
+func TestIntermediateFlagInherited(t *testing.T) {
+	for _, args := range [][]string{
+		{"remote", "--name", "x", "add"},
+		{"remote", "add", "--name", "x"},
+	} {
+		p := flaggy.NewParser("ourtool")
+		remote := flaggy.NewSubcommand("remote")
+		add := flaggy.NewSubcommand("add")
+		p.AttachSubcommand(remote, 1)
+		remote.AttachSubcommand(add, 1)
+		var name string
+		remote.String(&name, "n", "name", "remote name")
+		err := p.ParseArgs(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != "x" || !add.Used {
+			t.Fatal("expected the remote flag to be set with", args, "got", name, add.Used)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package flaggy
+
+import "os"
+
+// terminalWidth returns 0 because detecting the width of a terminal is not
+// supported on this platform
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,191 @@
+package flaggy
+
+import (
+	"flag"
+	"log"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+// Validator checks the value of a flag or positional value after it has been
+// assigned.  The value is the dereferenced assignment var, such as an int for
+// an *int flag or a []string for an *[]string flag.
+type Validator func(value interface{}) error
+
+// Min validates that a numeric value, or every number in a slice, is at
+// least min
+func Min(min float64) Validator {
+	return func(value interface{}) error {
+		return eachNumber(value, func(n float64) error {
+			if n < min {
+				return newMessageError(MessageValidateMin, "min", formatNumber(min))
+			}
+			return nil
+		})
+	}
+}
+
+// Max validates that a numeric value, or every number in a slice, is at
+// most max
+func Max(max float64) Validator {
+	return func(value interface{}) error {
+		return eachNumber(value, func(n float64) error {
+			if n > max {
+				return newMessageError(MessageValidateMax, "max", formatNumber(max))
+			}
+			return nil
+		})
+	}
+}
+
+// Range validates that a numeric value, or every number in a slice, is
+// between min and max inclusive
+func Range(min float64, max float64) Validator {
+	return func(value interface{}) error {
+		return eachNumber(value, func(n float64) error {
+			if n < min || n > max {
+				return newMessageError(MessageValidateRange, "min", formatNumber(min), "max", formatNumber(max))
+			}
+			return nil
+		})
+	}
+}
+
+// Length validates that a string value, or every string in a slice, has a
+// length between min and max inclusive.  A max of 0 is unlimited.
+func Length(min int, max int) Validator {
+	return func(value interface{}) error {
+		return eachString(value, func(s string) error {
+			if len(s) < min {
+				return newMessageError(MessageValidateMinLength, "min", strconv.Itoa(min))
+			}
+			if max > 0 && len(s) > max {
+				return newMessageError(MessageValidateMaxLength, "max", strconv.Itoa(max))
+			}
+			return nil
+		})
+	}
+}
+
+// Match validates that a string value, or every string in a slice, matches
+// the regular expression pattern.  Panics if the pattern does not compile.
+func Match(pattern string) Validator {
+	re := regexp.MustCompile(pattern)
+	return func(value interface{}) error {
+		return eachString(value, func(s string) error {
+			if !re.MatchString(s) {
+				return newMessageError(MessageValidateMatch, "pattern", pattern)
+			}
+			return nil
+		})
+	}
+}
+
+// NonEmpty validates that a string, slice or map value is not empty and that
+// a slice does not contain empty strings
+func NonEmpty() Validator {
+	return func(value interface{}) error {
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+			if v.Len() == 0 {
+				return newMessageError(MessageValidateNonEmpty)
+			}
+		}
+		return eachString(value, func(s string) error {
+			if s == "" {
+				return newMessageError(MessageValidateNoEmptyValues)
+			}
+			return nil
+		})
+	}
+}
+
+// runValidators runs each validator against the value of the assignment var
+// and returns an error with the message with the key, naming the flag or
+// positional and the raw value
+func runValidators(validators []Validator, assignmentVar interface{}, key MessageKey, name string, rawValue string) error {
+	if len(validators) == 0 {
+		return nil
+	}
+	// flag.Value types are validated as they are instead of dereferenced
+	value := assignmentVar
+	if _, isValue := assignmentVar.(flag.Value); !isValue {
+		value = reflect.ValueOf(assignmentVar).Elem().Interface()
+	}
+	for _, validator := range validators {
+		if err := validator(value); err != nil {
+			return &MessageError{Key: key, Params: []string{"value", rawValue, "name", name}, Err: err}
+		}
+	}
+	return nil
+}
+
+// Validate adds validators to the flag with the specified short or long
+// name, or to the positional value with the specified name.  Validators run
+// in order right after each value is assigned.
+func (sc *Subcommand) Validate(name string, validators ...Validator) {
+	for _, f := range sc.Flags {
+		if f.HasName(name) {
+			f.Validators = append(f.Validators, validators...)
+			return
+		}
+	}
+	for _, pv := range sc.PositionalFlags {
+		if pv.Name == name {
+			pv.Validators = append(pv.Validators, validators...)
+			return
+		}
+	}
+	log.Panicln("Unable to add validators because no flag or positional value named " + name + " exists on subcommand " + sc.Name)
+}
+
+// eachNumber calls fn with a numeric value or every number in a slice.
+// Values that are not numeric are ignored.
+func eachNumber(value interface{}, fn func(float64) error) error {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if err := eachNumber(v.Index(i).Interface(), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fn(float64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fn(float64(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return fn(v.Float())
+	}
+	return nil
+}
+
+// eachString calls fn with a string value or every string in a slice.
+// Values that are not strings are ignored.
+func eachString(value interface{}, fn func(string) error) error {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return fn(v.String())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := fn(v.Index(i).String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatNumber formats a validator bound without trailing zeros
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
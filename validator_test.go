@@ -0,0 +1,96 @@
+package flaggy_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+)
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator flaggy.Validator
+		args      []string
+		wantErr   string
+	}{
+		{name: "range_ok", validator: flaggy.Range(1, 65535), args: []string{"--port", "8080"}},
+		{name: "range_low", validator: flaggy.Range(1, 65535), args: []string{"--port", "0"}, wantErr: `Invalid value "0" for flag --port: must be between 1 and 65535`},
+		{name: "min", validator: flaggy.Min(10), args: []string{"--port", "9"}, wantErr: `Invalid value "9" for flag --port: must be at least 10`},
+		{name: "max", validator: flaggy.Max(10), args: []string{"-p=11"}, wantErr: `Invalid value "11" for flag --port: must be at most 10`},
+		{name: "custom", validator: func(value interface{}) error {
+			if value.(int)%2 != 0 {
+				return errors.New("must be even")
+			}
+			return nil
+		}, args: []string{"--port", "3"}, wantErr: `Invalid value "3" for flag --port: must be even`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := flaggy.NewParser("TestValidators")
+			var port int
+			p.Int(&port, "p", "port", "the port")
+			p.Validate("port", tt.validator)
+			err := p.ParseArgs(tt.args)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("got: %s; want: no error", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("got: %v; want: %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStringValidators(t *testing.T) {
+	p := flaggy.NewParser("TestStringValidators")
+	var name string
+	var tags []string
+	p.String(&name, "n", "name", "the name")
+	p.StringSlice(&tags, "t", "tag", "the tags")
+	p.Validate("n", flaggy.Length(2, 8), flaggy.Match("^[a-z]+$"))
+	p.Validate("tag", flaggy.NonEmpty())
+	if err := p.ParseArgs([]string{"-n", "abc", "-t", "a,b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p = flaggy.NewParser("TestStringValidators")
+	p.String(&name, "n", "name", "the name")
+	p.Validate("n", flaggy.Length(2, 8), flaggy.Match("^[a-z]+$"))
+	err := p.ParseArgs([]string{"-n", "abc1"})
+	if err == nil || !strings.Contains(err.Error(), "must match") {
+		t.Fatalf("got: %v; want: a regexp validation error", err)
+	}
+
+	p = flaggy.NewParser("TestStringValidators")
+	p.StringSlice(&tags, "t", "tag", "the tags")
+	p.Validate("tag", flaggy.NonEmpty())
+	err = p.ParseArgs([]string{"-t", "a,"})
+	if err == nil || !strings.Contains(err.Error(), "must not contain empty values") {
+		t.Fatalf("got: %v; want: a non-empty validation error", err)
+	}
+}
+
+func TestPositionalValidators(t *testing.T) {
+	p := flaggy.NewParser("TestPositionalValidators")
+	var ratio float64
+	p.AddTypedPositionalValue(&ratio, "RATIO", 1, true, "the ratio")
+	p.Validate("RATIO", flaggy.Range(0, 1))
+	err := p.ParseArgs([]string{"1.5"})
+	want := `Invalid value "1.5" for positional RATIO: must be between 0 and 1`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got: %v; want: %s", err, want)
+	}
+}
+
+func TestValidateUnknownName(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Expected crash when adding validators to an unknown flag")
+		}
+	}()
+	p := flaggy.NewParser("TestValidateUnknownName")
+	p.Validate("missing", flaggy.NonEmpty())
+}
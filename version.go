@@ -0,0 +1,138 @@
+package flaggy
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/template"
+)
+
+// versionCommandName is the name of the built-in version subcommand
+const versionCommandName = "version"
+
+// versionOutputFlagName is the name of the flag that selects the format of
+// version output
+const versionOutputFlagName = "output"
+
+// versionFormatJSON is the value of --output that shows the version as JSON
+const versionFormatJSON = "json"
+
+// defaultVersionTemplate is the version template used by default
+const defaultVersionTemplate = `Version: {{.Version}}{{if .Revision}}
+Revision: {{.Revision}}{{if .Modified}} (modified){{end}}{{end}}{{if .CommitTime}}
+Committed: {{.CommitTime}}{{end}}{{if .GoVersion}}
+Go: {{.GoVersion}}{{end}}
+`
+
+// VersionInfo holds the values needed to render version output.  Everything
+// but the name and version is read from the build info embedded in the
+// program by the go command.
+type VersionInfo struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`              // the parser's version, or the main module's version when the parser has none
+	Module     string `json:"module,omitempty"`     // the path of the main module
+	Revision   string `json:"revision,omitempty"`   // the VCS revision the program was built from
+	Modified   bool   `json:"modified,omitempty"`   // indicates the program was built from a modified working tree
+	CommitTime string `json:"commitTime,omitempty"` // the time of the VCS revision the program was built from
+	GoVersion  string `json:"goVersion,omitempty"`  // the version of Go the program was built with
+}
+
+// VersionInfo returns the values shown in version output
+func (p *Parser) VersionInfo() VersionInfo {
+	info := VersionInfo{
+		Name:      p.Name,
+		Version:   p.Version,
+		GoVersion: runtime.Version(),
+	}
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = buildInfo.Main.Path
+	if p.Version == defaultVersion && buildInfo.Main.Version != "" && buildInfo.Main.Version != "(devel)" {
+		info.Version = buildInfo.Main.Version
+	}
+	addVCSInfo(&info, buildInfo)
+	return info
+}
+
+// SetVersionTemplate sets the go template this parser will use when
+// rendering version output.  The template is executed with a VersionInfo.
+func (p *Parser) SetVersionTemplate(tmpl string) error {
+	var err error
+	p.VersionTemplate, err = template.New(versionFlagLongName).Parse(tmpl)
+	return err
+}
+
+// ShowVersionAndExit shows the version of this parser
+func (p *Parser) ShowVersionAndExit() {
+	p.showVersion("")
+	p.exit(0)
+}
+
+// ShowVersionJSONAndExit shows the version of this parser as a JSON object
+func (p *Parser) ShowVersionJSONAndExit() {
+	p.showVersion(versionFormatJSON)
+	p.exit(0)
+}
+
+// showRequestedVersionAndExit shows the version of this parser in the format
+// requested among the args and exits
+func (p *Parser) showRequestedVersionAndExit(args []string) {
+	p.showVersion(p.versionFormat(args))
+	p.exit(0)
+}
+
+// showVersion writes version output in the specified format to Stdout, using
+// the version template unless the format is json
+func (p *Parser) showVersion(format string) {
+	info := p.VersionInfo()
+	if format == versionFormatJSON {
+		out, err := json.Marshal(info)
+		if err != nil {
+			fmt.Fprintln(p.Output, "Error rendering version:", err)
+			return
+		}
+		fmt.Fprintln(p.Stdout, string(out))
+		return
+	}
+
+	if err := p.VersionTemplate.Execute(p.Stdout, info); err != nil {
+		fmt.Fprintln(p.Output, "Error rendering version template:", err)
+	}
+}
+
+// versionFormat returns the format version output was requested in with an
+// --output flag among the args. ex) --version --output json.  --output is
+// left to the program when it has a flag of its own with that name.
+func (p *Parser) versionFormat(args []string) string {
+	for _, f := range collectAllNestedFlags(&p.Subcommand) {
+		if f.HasName(versionOutputFlagName) {
+			return ""
+		}
+	}
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := parseFlagToName(arg)
+		if !strings.HasPrefix(arg, "-") || !strings.HasPrefix(name, versionOutputFlagName) {
+			continue
+		}
+		if name == versionOutputFlagName && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(name, versionOutputFlagName+"=") {
+			return strings.TrimPrefix(name, versionOutputFlagName+"=")
+		}
+	}
+	return ""
+}
+
+// hasVersionCommand indicates that the built-in version subcommand is
+// enabled.  A subcommand named version takes precedence over it.
+func (p *Parser) hasVersionCommand() bool {
+	return p.ShowVersionWithVersionCommand && !p.hasSubcommandAtFirstPosition(versionCommandName)
+}
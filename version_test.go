@@ -0,0 +1,78 @@
+package flaggy_test
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/diegosz/flaggy"
+	"github.com/diegosz/flaggy/flaggytest"
+)
+
+func TestVersionOutput(t *testing.T) {
+	p := flaggy.NewParser("mytool")
+	p.Version = "1.2.3"
+	result := flaggytest.Run(p, "--version")
+	if result.ExitCode != 0 || result.Stdout != "Version: 1.2.3\nGo: "+runtime.Version()+"\n" {
+		t.Fatalf("unexpected version output %q", result.Stdout)
+	}
+
+	p = flaggy.NewParser("mytool")
+	p.Version = "1.2.3"
+	result = flaggytest.Run(p, "--version", "--output", "json")
+	var info flaggy.VersionInfo
+	if err := json.Unmarshal([]byte(result.Stdout), &info); err != nil {
+		t.Fatalf("expected JSON version output, got %q: %s", result.Stdout, err)
+	}
+	if info.Name != "mytool" || info.Version != "1.2.3" || info.GoVersion != runtime.Version() {
+		t.Fatalf("unexpected version info %+v", info)
+	}
+
+	p = flaggy.NewParser("mytool")
+	p.Version = "1.2.3"
+	var output string
+	p.String(&output, "o", "output", "The output format.")
+	result = flaggytest.Run(p, "--output", "json", "--version")
+	if result.Stdout != "Version: 1.2.3\nGo: "+runtime.Version()+"\n" {
+		t.Fatalf("expected the program's --output flag to be left alone, got %q", result.Stdout)
+	}
+
+	p = flaggy.NewParser("mytool")
+	p.Version = "1.2.3"
+	if err := p.SetVersionTemplate("{{.Name}} {{.Version}}\n"); err != nil {
+		t.Fatal(err)
+	}
+	result = flaggytest.Run(p, "--version")
+	if result.Stdout != "mytool 1.2.3\n" {
+		t.Fatalf("expected the version template to be used, got %q", result.Stdout)
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	p := flaggy.NewParser("mytool")
+	p.Version = "1.2.3"
+	p.ShowVersionWithVersionCommand = true
+	p.AttachSubcommand(flaggy.NewSubcommand("serve"), 1)
+	result := flaggytest.Run(p, "version", "--output=json")
+	var info flaggy.VersionInfo
+	if err := json.Unmarshal([]byte(result.Stdout), &info); err != nil || info.Version != "1.2.3" {
+		t.Fatalf("expected JSON version output, got %q: %v", result.Stdout, err)
+	}
+
+	p = flaggy.NewParser("mytool")
+	p.ShowVersionWithVersionCommand = true
+	version := flaggy.NewSubcommand("version")
+	p.AttachSubcommand(version, 1)
+	result = flaggytest.Run(p, "version")
+	if result.Exited || result.Subcommand != version {
+		t.Fatal("expected the version subcommand to run, got:", result.Stdout, result.Stderr)
+	}
+
+	p = flaggy.NewParser("mytool")
+	p.Version = "1.2.3"
+	result = flaggytest.Run(p, "version")
+	if result.ExitCode != 0 || !strings.HasPrefix(result.Stdout, "Version: 1.2.3\n") {
+		t.Fatal("expected version to be the built-in version flag when disabled, got:", result.ExitCode, result.Stdout, result.Stderr)
+	}
+}
@@ -0,0 +1,135 @@
+package flaggy
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// helpTemplateFuncs are the functions available to help templates
+var helpTemplateFuncs = template.FuncMap{
+	"wrap":         wrap,
+	"displayWidth": displayWidth,
+	"pad":          pad,
+}
+
+// wideRanges are the ranges of East Asian wide and fullwidth characters,
+// which take two columns in a terminal
+var wideRanges = []struct{ first, last rune }{
+	{0x1100, 0x115F},
+	{0x231A, 0x231B},
+	{0x2329, 0x232A},
+	{0x23E9, 0x23EC},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xA960, 0xA97F},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF},
+	{0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+// runeWidth returns the number of terminal columns a rune takes.  Combining
+// marks and other zero width characters take none and East Asian wide
+// characters take two.
+func runeWidth(r rune) int {
+	if r == 0 || unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r >= wide.first && r <= wide.last {
+			return 2
+		}
+	}
+	return 1
+}
+
+// displayWidth returns the number of terminal columns a string takes.  ANSI
+// escape sequences, such as those that style help output, take none.
+func displayWidth(s string) int {
+	var width int
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "\x1b[") {
+			// escape sequences end with a byte from @ to ~
+			end := strings.IndexFunc(s[i+2:], func(r rune) bool { return r >= '@' && r <= '~' })
+			if end >= 0 {
+				i += 2 + end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// pad adds spaces to the end of a string until it takes the specified number
+// of terminal columns
+func pad(s string, width int) string {
+	return s + makeSpacer(s, width)
+}
+
+// wrap wraps text that starts at the specified column so that no line is
+// wider than width.  Lines after the first are indented to line up with the
+// first word of the text.  The text is returned unchanged when it fits or
+// when width is 0 or less.
+func wrap(width int, column int, text string) string {
+	if width <= 0 || column+displayWidth(text) <= width {
+		return text
+	}
+
+	leading := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	indent := column + len(leading)
+	var wrapped strings.Builder
+	wrapped.WriteString(leading)
+	lineWidth := indent
+	for i, word := range strings.Fields(text) {
+		wordWidth := displayWidth(word)
+		if i > 0 {
+			// words wider than the space left start a new line unless the line
+			// is still empty
+			if lineWidth+1+wordWidth > width && lineWidth > indent {
+				wrapped.WriteString("\n" + strings.Repeat(" ", indent))
+				lineWidth = indent
+			} else {
+				wrapped.WriteString(" ")
+				lineWidth++
+			}
+		}
+		wrapped.WriteString(word)
+		lineWidth += wordWidth
+	}
+	return wrapped.String()
+}
+
+// helpWidth returns the width help output is wrapped to.  HelpWidth is used
+// when set, then the COLUMNS environment variable, then the width of the
+// terminal Output writes to.  0 means help is not wrapped.
+func (p *Parser) helpWidth() int {
+	if p.HelpWidth != 0 {
+		if p.HelpWidth < 0 {
+			return 0
+		}
+		return p.HelpWidth
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if f, ok := p.Output.(*os.File); ok {
+		return terminalWidth(f)
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package flaggy
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := map[string]int{
+		"abc":      3,
+		"日本語":      6,
+		"e\u0301":  1, // e with a combining acute accent
+		"ｆｕｌｌ":     8,
+		"a\u200bb": 2, // zero width space
+	}
+	for s, want := range tests {
+		if got := displayWidth(s); got != want {
+			t.Errorf("displayWidth(%q) = %d; want %d", s, got, want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	text := "   the quick brown fox jumps over the lazy dog"
+	if got := wrap(0, 10, text); got != text {
+		t.Fatalf("expected no wrapping without a width, got %q", got)
+	}
+	if got := wrap(100, 10, text); got != text {
+		t.Fatalf("expected no wrapping when text fits, got %q", got)
+	}
+	want := "   the quick brown\n             fox jumps over\n             the lazy dog"
+	if got := wrap(30, 10, text); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+	want = "   日本語 日本語\n   日本語"
+	if got := wrap(17, 0, "   日本語 日本語 日本語"); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHelpWidth(t *testing.T) {
+	p := NewParser("width")
+	os.Setenv("COLUMNS", "72")
+	defer os.Unsetenv("COLUMNS")
+	if got := p.helpWidth(); got != 72 {
+		t.Fatalf("expected COLUMNS to be used, got %d", got)
+	}
+	p.HelpWidth = 40
+	if got := p.helpWidth(); got != 40 {
+		t.Fatalf("expected HelpWidth override, got %d", got)
+	}
+	p.HelpWidth = -1
+	if got := p.helpWidth(); got != 0 {
+		t.Fatalf("expected wrapping to be disabled, got %d", got)
+	}
+}